@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// affectedStack is a stack containing resources of the type given with -affected-by.
+type affectedStack struct {
+	Account     string
+	Region      string
+	StackName   string
+	StackStatus string
+	// Resources are the logical ids of the matching resources.
+	Resources []string
+	// logicalIDs holds the entries of Resources, so a resource listed again is only recorded once.
+	logicalIDs map[string]bool
+}
+
+// validateResourceTypePattern checks that pattern is a valid resource type pattern, e.g. AWS::EC2::SecurityGroup or AWS::EC2::*.
+func validateResourceTypePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("-affected-by is not a valid resource type pattern: %s", pattern)
+	}
+
+	return nil
+}
+
+// matchesResourceType reports whether resourceType matches pattern.
+func matchesResourceType(pattern string, resourceType string) bool {
+	// The pattern was validated by parseOptions.
+	matched, _ := path.Match(pattern, resourceType)
+
+	return matched
+}
+
+// affectedStacks collects, in scan order, the stacks containing resources of a resource type.
+type affectedStacks struct {
+	pattern string
+	stacks  []*affectedStack
+	byID    map[string]*affectedStack
+}
+
+// newAffectedStacks returns a collector for the stacks with resources matching pattern.
+func newAffectedStacks(pattern string) *affectedStacks {
+	return &affectedStacks{pattern: pattern, byID: map[string]*affectedStack{}}
+}
+
+// Add records the stack of resource when the resource type matches. Resources are keyed by logical id, so a
+// resource listed again is only recorded once.
+func (a *affectedStacks) Add(account string, region string, stack cfTypes.StackSummary, resource cfTypes.StackResourceSummary) {
+	if !matchesResourceType(a.pattern, aws.ToString(resource.ResourceType)) {
+		return
+	}
+
+	stackID := aws.ToString(stack.StackId)
+
+	entry, ok := a.byID[stackID]
+	if !ok {
+		entry = &affectedStack{
+			Account:     account,
+			Region:      region,
+			StackName:   aws.ToString(stack.StackName),
+			StackStatus: string(stack.StackStatus),
+			logicalIDs:  map[string]bool{},
+		}
+		a.byID[stackID] = entry
+		a.stacks = append(a.stacks, entry)
+	}
+
+	logicalID := aws.ToString(resource.LogicalResourceId)
+	if entry.logicalIDs[logicalID] {
+		return
+	}
+
+	entry.logicalIDs[logicalID] = true
+	entry.Resources = append(entry.Resources, logicalID)
+}
+
+// printAffectedStacksReport logs the stacks to review before changing resources of the type.
+func printAffectedStacksReport(affected *affectedStacks) {
+	log.Printf("Stacks containing %s resources: %d\n", affected.pattern, len(affected.stacks))
+
+	for _, stack := range affected.stacks {
+		log.Printf("- %s (%s, %s) %s: %d resource(s)", stack.StackName, stack.Account, stack.Region, stack.StackStatus,
+			len(stack.Resources))
+
+		for _, logicalID := range stack.Resources {
+			log.Printf("  - %s", logicalID)
+		}
+	}
+}
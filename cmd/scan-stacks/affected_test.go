@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestAffectedStacksRecordsEachResourceOnce(t *testing.T) {
+	logs := captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	queue := testStack(testDefaultRegion, "queue", cfTypes.StackStatusUpdateComplete)
+	group := testResource("WebSecurityGroup", "AWS::EC2::SecurityGroup", "sg-0123")
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			// The security group is listed twice, as a retried page would return it again.
+			*web.StackId: {
+				group,
+				testResource("Instance", "AWS::EC2::Instance", "i-0123"),
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				group,
+			},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "jobs")},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-affected-by", "AWS::EC2::*", "-concurrency", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	// Adding a resource again after the scan records nothing either.
+	s.affected.Add(testAccount, testDefaultRegion, web, group)
+
+	if len(s.affected.stacks) != 1 || s.affected.stacks[0].StackName != "web" {
+		t.Fatalf("affected stacks = %+v, want only web", s.affected.stacks)
+	}
+
+	if got, want := s.affected.stacks[0].Resources, []string{"WebSecurityGroup", "Instance"}; !slices.Equal(got, want) {
+		t.Errorf("resources of web = %v, want %v", got, want)
+	}
+
+	printAffectedStacksReport(s.affected)
+
+	if want := "- web (" + testAccount + ", " + testDefaultRegion + ") CREATE_COMPLETE: 2 resource(s)"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
+
+func TestValidateResourceTypePattern(t *testing.T) {
+	if err := validateResourceTypePattern("AWS::EC2::*"); err != nil {
+		t.Errorf("validateResourceTypePattern(AWS::EC2::*) error: %v", err)
+	}
+
+	if err := validateResourceTypePattern("AWS::EC2::["); err == nil {
+		t.Error("validateResourceTypePattern(AWS::EC2::[) returned no error")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// aggregatePoint is the number of stacks and resources of one account and region in one prior scan.
+type aggregatePoint struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Account     string    `json:"account"`
+	Region      string    `json:"region"`
+	Stacks      int       `json:"stacks"`
+	Resources   int       `json:"resources"`
+}
+
+// aggregateCSVHeader is the header row of the CSV time series; there is one row per point.
+var aggregateCSVHeader = []string{"generated_at", "account", "region", "stacks", "resources"}
+
+// aggregateReports returns the time series of the stack and resource counts per account and region of
+// the JSON reports matching pattern, ordered by scan time, account and region, with a zero point for each
+// region the report notes as without stacks. Stacks are deduplicated under dedupKey within each report.
+func aggregateReports(pattern string, dedupKey string) ([]aggregatePoint, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -aggregate pattern: %s", pattern)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no reports match %s", pattern)
+	}
+
+	var points []aggregatePoint
+
+	for _, path := range paths {
+		report, rerr := readScanReport(path)
+		if rerr != nil {
+			return nil, rerr
+		}
+
+		merged := mergeReports(dedupKey, report)
+
+		for _, region := range merged.Regions {
+			point := aggregatePoint{
+				GeneratedAt: report.GeneratedAt.UTC(),
+				Account:     region.Account,
+				Region:      region.Region,
+				Stacks:      len(region.Stacks),
+			}
+
+			for _, stack := range region.Stacks {
+				point.Resources += len(stack.Resources)
+			}
+
+			points = append(points, point)
+		}
+
+		// Regions left out for having no stacks were scanned too, and count as zero.
+		for _, key := range report.RegionsWithoutStacks {
+			account, region, ok := parseRegionCoverageKey(key)
+			if !ok {
+				return nil, fmt.Errorf("invalid region without stacks in %s: %s", path, key)
+			}
+
+			points = append(points, aggregatePoint{GeneratedAt: report.GeneratedAt.UTC(), Account: account, Region: region})
+		}
+	}
+
+	slices.SortStableFunc(points, func(a, b aggregatePoint) int {
+		return cmp.Or(a.GeneratedAt.Compare(b.GeneratedAt), cmp.Compare(a.Account, b.Account), cmp.Compare(a.Region, b.Region))
+	})
+
+	return points, nil
+}
+
+// writeAggregate writes points to w as JSON, CSV or, for the text outputs, a table.
+func writeAggregate(w io.Writer, output string, points []aggregatePoint) error {
+	switch output {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(points); err != nil {
+			return fmt.Errorf("failed to write aggregate: %w", err)
+		}
+
+		return nil
+	case OutputCSV:
+		writer := csv.NewWriter(w)
+
+		if err := writer.Write(aggregateCSVHeader); err != nil {
+			return fmt.Errorf("failed to write aggregate: %w", err)
+		}
+
+		for _, point := range points {
+			row := []string{
+				point.GeneratedAt.Format(time.RFC3339), point.Account, point.Region,
+				strconv.Itoa(point.Stacks), strconv.Itoa(point.Resources),
+			}
+
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write aggregate: %w", err)
+			}
+		}
+
+		writer.Flush()
+
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write aggregate: %w", err)
+		}
+
+		return nil
+	default:
+		table := tabwriter.NewWriter(w, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+
+		fmt.Fprintln(table, "GENERATED AT\tACCOUNT\tREGION\tSTACKS\tRESOURCES")
+
+		for _, point := range points {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\n", point.GeneratedAt.Format(time.RFC3339), point.Account, point.Region,
+				point.Stacks, point.Resources)
+		}
+
+		if err := table.Flush(); err != nil {
+			return fmt.Errorf("failed to write aggregate: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAggregateDatedReports(t *testing.T) {
+	points, err := aggregateReports(filepath.Join("testdata", "aggregate", "*.json"), DedupKeyStackID)
+	if err != nil {
+		t.Fatalf("aggregateReports error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeAggregate(&buf, OutputCSV, points); err != nil {
+		t.Fatalf("writeAggregate error: %v", err)
+	}
+
+	// The second report counts its duplicated stack once, and the regions it and the third report
+	// left out for having no stacks count as zero.
+	want := []string{
+		"generated_at,account,region,stacks,resources",
+		"2025-01-01T06:00:00Z,111111111111,us-east-1,0,0",
+		"2025-01-01T06:00:00Z,111111111111,us-west-2,2,3",
+		"2025-01-02T06:00:00Z,111111111111,us-east-1,0,0",
+		"2025-01-02T06:00:00Z,111111111111,us-west-2,1,3",
+		"2025-01-03T06:00:00Z,111111111111,us-east-1,1,1",
+		"2025-01-03T06:00:00Z,111111111111,us-west-2,0,0",
+	}
+
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); !slices.Equal(got, want) {
+		t.Errorf("aggregate =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestAggregateWithoutMatchingReports(t *testing.T) {
+	if _, err := aggregateReports(filepath.Join(t.TempDir(), "*.json"), DedupKeyStackID); err == nil {
+		t.Error("aggregateReports returned no error without reports")
+	}
+}
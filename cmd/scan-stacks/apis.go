@@ -0,0 +1,48 @@
+package main
+
+import (
+	"slices"
+)
+
+// apiOperations returns every AWS API operation, in IAM action form (service:Operation),
+// that scan-stacks may invoke with the given options. Keep this in sync when adding AWS calls.
+func apiOperations(opts *options) []string {
+	if opts.outputsAsEnv || opts.waitForStable {
+		return []string{"cloudformation:DescribeStacks"}
+	}
+
+	operations := []string{
+		"sts:GetCallerIdentity",
+		"ec2:DescribeRegions",
+		"cloudformation:ListStacks",
+	}
+
+	if !opts.inProgressCount {
+		operations = append(operations, "cloudformation:ListStackResources")
+
+		if opts.needsStackTags() {
+			// ListStacks does not return the stack tags; DescribeStacks does.
+			operations = append(operations, "cloudformation:DescribeStacks")
+		}
+	}
+
+	if opts.org {
+		operations = append(operations, "organizations:ListAccounts", "sts:AssumeRole")
+	}
+
+	if opts.azs {
+		operations = append(operations, "ec2:DescribeAvailabilityZones")
+	}
+
+	if opts.rootCause || opts.resourceTimeline {
+		operations = append(operations, "cloudformation:DescribeStackEvents")
+	}
+
+	if opts.eventBusName != "" {
+		operations = append(operations, "events:PutEvents")
+	}
+
+	slices.Sort(operations)
+
+	return slices.Compact(operations)
+}
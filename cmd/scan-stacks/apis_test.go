@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAPIOperationsMatchEnabledFeatures(t *testing.T) {
+	scan := []string{"cloudformation:ListStackResources", "cloudformation:ListStacks", "ec2:DescribeRegions", "sts:GetCallerIdentity"}
+	tags := append(slices.Clone(scan), "cloudformation:DescribeStacks")
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"default", nil, scan},
+		{"single stack", []string{"-stack", "app", "-outputs-as-env"}, []string{"cloudformation:DescribeStacks"}},
+		{"wait for stable", []string{"-stack", "app", "-wait-for-stable"}, []string{"cloudformation:DescribeStacks"}},
+		{"in progress count", []string{"-in-progress-count"},
+			[]string{"cloudformation:ListStacks", "ec2:DescribeRegions", "sts:GetCallerIdentity"}},
+		{"org", []string{"-org"}, append(slices.Clone(scan), "organizations:ListAccounts", "sts:AssumeRole")},
+		{"azs", []string{"-azs"}, append(slices.Clone(scan), "ec2:DescribeAvailabilityZones")},
+		{"stackset origin", []string{"-stackset-origin"}, tags},
+		{"tags", []string{"-group-by-tag", "team"}, tags},
+		{"policy", []string{"-policy", "rules.txt"}, tags},
+		{"root cause and timeline", []string{"-root-cause", "-resource-timeline"},
+			append(slices.Clone(scan), "cloudformation:DescribeStackEvents")},
+		{"eventbridge", []string{"-eventbridge", "ops-bus"}, append(slices.Clone(scan), "events:PutEvents")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptions("scan-stacks", tt.args)
+			if err != nil {
+				t.Fatalf("parseOptions(%v) error: %v", tt.args, err)
+			}
+
+			want := slices.Sorted(slices.Values(tt.want))
+			if got := apiOperations(opts); !slices.Equal(got, want) {
+				t.Errorf("apiOperations(%v) = %v, want %v", tt.args, got, want)
+			}
+		})
+	}
+}
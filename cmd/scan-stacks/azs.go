@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// getAvailabilityZones retrieves the availability zones of the region of cfg.
+func getAvailabilityZones(ctx context.Context, cfg aws.Config) ([]ec2Types.AvailabilityZone, error) {
+	ec2Client := ec2.NewFromConfig(cfg)
+
+	output, err := ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe availability zones: %w", err)
+	}
+
+	return output.AvailabilityZones, nil
+}
+
+// availabilityZoneNames returns the zone names, logging each zone with its id and state.
+func availabilityZoneNames(zones []ec2Types.AvailabilityZone) []string {
+	names := make([]string, 0, len(zones))
+
+	for _, zone := range zones {
+		log.Printf("  - Availability Zone: %s (%s, %s)", aws.ToString(zone.ZoneName), aws.ToString(zone.ZoneId), zone.State)
+
+		names = append(names, aws.ToString(zone.ZoneName))
+	}
+
+	return names
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// testZone returns an available zone of region with the given suffix and zone id.
+func testZone(region string, suffix string, zoneID string) ec2Types.AvailabilityZone {
+	return ec2Types.AvailabilityZone{
+		ZoneName: aws.String(region + suffix),
+		ZoneId:   aws.String(zoneID),
+		State:    ec2Types.AvailabilityZoneStateAvailable,
+	}
+}
+
+func TestAvailabilityZonesListedPerRegion(t *testing.T) {
+	logs := captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "eu-west-1"},
+		zones: map[string][]ec2Types.AvailabilityZone{
+			testDefaultRegion: {testZone(testDefaultRegion, "a", "usw2-az1"), testZone(testDefaultRegion, "b", "usw2-az2")},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-azs", "-output", "json", "-concurrency", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	if len(s.report.Regions) != 2 {
+		t.Fatalf("regions = %+v, want 2", s.report.Regions)
+	}
+
+	if got, want := s.report.Regions[0].AvailabilityZones, []string{"us-west-2a", "us-west-2b"}; !slices.Equal(got, want) {
+		t.Errorf("%s zones = %v, want %v", testDefaultRegion, got, want)
+	}
+
+	// The zones of eu-west-1 fail to list; the region is still scanned, without zones.
+	if got := s.report.Regions[1].AvailabilityZones; got != nil {
+		t.Errorf("eu-west-1 zones = %v, want none", got)
+	}
+
+	for _, want := range []string{
+		"  - Availability Zone: us-west-2a (usw2-az1, available)",
+		"  - Availability Zone: us-west-2b (usw2-az2, available)",
+		"Error calling getAvailabilityZones: no availability zones in eu-west-1",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log does not contain %q:\n%s", want, logs)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// ExitCodeDeviation is the exit code used with -fail-on-deviation when the scan deviates from the baseline.
+const ExitCodeDeviation = 4
+
+// Deviation types reported against the baseline.
+const (
+	DeviationNewStack         = "NEW_STACK"
+	DeviationRemovedStack     = "REMOVED_STACK"
+	DeviationStatusRegression = "STATUS_REGRESSION"
+)
+
+// baselineDeviation is a difference between the scan and the known-good baseline.
+type baselineDeviation struct {
+	Type           string
+	Account        string
+	Region         string
+	StackName      string
+	BaselineStatus string
+	CurrentStatus  string
+}
+
+// baselineStackKey identifies a stack across scans by account, region and name, as a replaced stack gets a new id.
+func baselineStackKey(region regionReport, stack stackReport) string {
+	return stackDedupKey(DedupKeyName, region, stack)
+}
+
+// isStatusRegression reports whether a stack that was not failed or rolled back in the baseline now is.
+func isStatusRegression(baselineStatus string, currentStatus string) bool {
+	return !isFailedOrRolledBackStackStatus(cfTypes.StackStatus(baselineStatus)) &&
+		isFailedOrRolledBackStackStatus(cfTypes.StackStatus(currentStatus))
+}
+
+// compareToBaseline returns the stacks that are new, removed or regressed in current compared to baseline.
+// Stacks are only reported removed from the account/regions present in current.
+func compareToBaseline(baseline *scanReport, current *scanReport) []baselineDeviation {
+	baselineStacks := map[string]stackReport{}
+
+	for _, region := range baseline.Regions {
+		for _, stack := range region.Stacks {
+			baselineStacks[baselineStackKey(region, stack)] = stack
+		}
+	}
+
+	var deviations []baselineDeviation
+
+	currentStacks := map[string]bool{}
+	scannedRegions := map[string]bool{}
+
+	for _, region := range current.Regions {
+		scannedRegions[region.Account+"/"+region.Region] = true
+
+		for _, stack := range region.Stacks {
+			key := baselineStackKey(region, stack)
+			currentStacks[key] = true
+
+			deviation := baselineDeviation{
+				Account:       region.Account,
+				Region:        region.Region,
+				StackName:     aws.ToString(stack.StackName),
+				CurrentStatus: stack.StackStatus,
+			}
+
+			known, ok := baselineStacks[key]
+			if !ok {
+				deviation.Type = DeviationNewStack
+				deviations = append(deviations, deviation)
+
+				continue
+			}
+
+			if isStatusRegression(known.StackStatus, stack.StackStatus) {
+				deviation.Type = DeviationStatusRegression
+				deviation.BaselineStatus = known.StackStatus
+				deviations = append(deviations, deviation)
+			}
+		}
+	}
+
+	for _, region := range baseline.Regions {
+		if !scannedRegions[region.Account+"/"+region.Region] {
+			// Regions that were not scanned, e.g. outside the shard or failing to list, remove nothing.
+			continue
+		}
+
+		for _, stack := range region.Stacks {
+			if currentStacks[baselineStackKey(region, stack)] {
+				continue
+			}
+
+			deviations = append(deviations, baselineDeviation{
+				Type:           DeviationRemovedStack,
+				Account:        region.Account,
+				Region:         region.Region,
+				StackName:      aws.ToString(stack.StackName),
+				BaselineStatus: stack.StackStatus,
+			})
+		}
+	}
+
+	return deviations
+}
+
+// printBaselineReport logs the deviations from the baseline.
+func printBaselineReport(deviations []baselineDeviation, path string) {
+	log.Printf("Deviations from baseline '%s': %d\n", path, len(deviations))
+
+	for _, deviation := range deviations {
+		switch deviation.Type {
+		case DeviationNewStack:
+			log.Printf("- %s: %s (%s, %s) %s", deviation.Type, deviation.StackName, deviation.Account, deviation.Region,
+				deviation.CurrentStatus)
+		case DeviationRemovedStack:
+			log.Printf("- %s: %s (%s, %s) was %s", deviation.Type, deviation.StackName, deviation.Account, deviation.Region,
+				deviation.BaselineStatus)
+		default:
+			log.Printf("- %s: %s (%s, %s) %s -> %s", deviation.Type, deviation.StackName, deviation.Account, deviation.Region,
+				deviation.BaselineStatus, deviation.CurrentStatus)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestCompareToBaselineFixture(t *testing.T) {
+	logs := captureLog(t)
+
+	baseline, err := readScanReport("testdata/baseline.json")
+	if err != nil {
+		t.Fatalf("readScanReport error: %v", err)
+	}
+
+	// Since the baseline, queue was replaced and its update rolled back, api was added and legacy deleted.
+	// db was already rolled back, and eu-west-1 is not scanned.
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks: map[string][]cfTypes.StackSummary{testDefaultRegion: {
+			testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete),
+			testStack(testDefaultRegion, "queue", cfTypes.StackStatusUpdateRollbackComplete),
+			testStack(testDefaultRegion, "db", cfTypes.StackStatusUpdateRollbackComplete),
+			testStack(testDefaultRegion, "api", cfTypes.StackStatusCreateComplete),
+		}},
+	}
+	s := newTestScanner(t, account, false, "-compare-to-baseline", "testdata/baseline.json", "-output", "json")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	deviations := compareToBaseline(mergeReports(s.opts.dedupKey, baseline), s.report)
+
+	var got []string
+	for _, deviation := range deviations {
+		got = append(got, deviation.Type+" "+deviation.StackName+" "+deviation.BaselineStatus+" "+deviation.CurrentStatus)
+	}
+
+	want := []string{
+		"STATUS_REGRESSION queue UPDATE_COMPLETE UPDATE_ROLLBACK_COMPLETE",
+		"NEW_STACK api  CREATE_COMPLETE",
+		"REMOVED_STACK legacy CREATE_COMPLETE ",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("deviations =\n%q\nwant\n%q", got, want)
+	}
+
+	printBaselineReport(deviations, "testdata/baseline.json")
+
+	for _, line := range []string{
+		"Deviations from baseline 'testdata/baseline.json': 3\n",
+		"- STATUS_REGRESSION: queue (111111111111, us-west-2) UPDATE_COMPLETE -> UPDATE_ROLLBACK_COMPLETE\n",
+		"- NEW_STACK: api (111111111111, us-west-2) CREATE_COMPLETE\n",
+		"- REMOVED_STACK: legacy (111111111111, us-west-2) was CREATE_COMPLETE\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+}
+
+func TestCompareToBaselineWithoutChanges(t *testing.T) {
+	baseline, err := readScanReport("testdata/baseline.json")
+	if err != nil {
+		t.Fatalf("readScanReport error: %v", err)
+	}
+
+	if deviations := compareToBaseline(baseline, baseline); len(deviations) != 0 {
+		t.Errorf("deviations of the baseline from itself = %+v, want none", deviations)
+	}
+}
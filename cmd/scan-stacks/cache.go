@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// DefaultCacheTTL is how long a cached report is reused by default.
+const DefaultCacheTTL = 15 * time.Minute
+
+// CacheFileMode is the file mode of cached reports, which describe the account and are not world readable.
+const CacheFileMode = 0o600
+
+// CacheDirMode is the file mode of the cache directory.
+const CacheDirMode = 0o700
+
+// CacheAccountsDir is the directory, under the cache directory, mapping access key ids to their account ids.
+const CacheAccountsDir = "accounts"
+
+// reportCacheKey holds the account and the options that change what the scan collects into the report.
+// Output options are left out, so a cached report can be rendered in another format. Keep this in sync when
+// adding options that change the report.
+type reportCacheKey struct {
+	// AccountID identifies the scanned account. STS and SSO session credentials change their access key id
+	// with every session, so the access key id alone would never hit.
+	AccountID    string `json:"accountId"`
+	Region       string `json:"region"`
+	Org          bool   `json:"org"`
+	OrgRole      string `json:"orgRole"`
+	Shard        string `json:"shard"`
+	AZs          bool   `json:"azs"`
+	StatusCounts bool   `json:"statusCounts"`
+	Timeline     bool   `json:"timeline"`
+}
+
+// newReportCacheKey returns the cache key of a scan of accountID, starting in region, with opts.
+func newReportCacheKey(opts *options, accountID string, region string) reportCacheKey {
+	key := reportCacheKey{
+		AccountID:    accountID,
+		Region:       region,
+		Org:          opts.org,
+		AZs:          opts.azs,
+		StatusCounts: opts.statusCounts,
+		Timeline:     opts.resourceTimeline,
+	}
+
+	if opts.org {
+		key.OrgRole = opts.orgRole
+	}
+
+	if opts.shard.Count > 1 {
+		key.Shard = opts.shard.String()
+	}
+
+	return key
+}
+
+// isReportCacheable reports whether everything the options ask for can be produced from a cached report.
+// Reports and sinks that are fed while scanning need a live scan.
+func isReportCacheable(opts *options) bool {
+	return opts.driftStale == 0 && opts.eventBusName == "" && opts.tfStatePath == "" && !opts.serviceMap &&
+		opts.emitEventsPath == "" && !opts.rootCause && !opts.inProgressCount && opts.resourceTemplate == "" &&
+		!(opts.statusCounts && opts.output == OutputText) && !opts.missingDeps && opts.affectedBy == "" &&
+		opts.kafkaTopic == "" && opts.output != OutputOCSF && !opts.showCredsSource && opts.groupByTag == "" &&
+		opts.policyPath == "" && !opts.showEndpoints && !opts.noPhysicalID &&
+		opts.sharedResource == ""
+}
+
+// reportCache stores the report of a scan on disk, keyed by the options and account of the scan.
+type reportCache struct {
+	path string
+	ttl  time.Duration
+}
+
+// newReportCache returns the cache of the report with key in dir.
+func newReportCache(dir string, ttl time.Duration, key reportCacheKey) (*reportCache, error) {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache key: %w", err)
+	}
+
+	sum := sha256.Sum256(encoded)
+
+	return &reportCache{path: filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), ttl: ttl}, nil
+}
+
+// callerAccountFunc returns the account id of the caller, e.g. with STS GetCallerIdentity.
+type callerAccountFunc func(ctx context.Context) (string, error)
+
+// openReportCache returns the cache of the report of a scan with cfg, starting in region, with opts. The
+// account of the key is resolved by cacheAccountID, so a cache hit with known credentials makes no AWS API
+// call beyond what resolving them takes.
+func openReportCache(ctx context.Context, cfg aws.Config, opts *options, region string,
+	callerAccount callerAccountFunc,
+) (*reportCache, error) {
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	accountID, err := cacheAccountID(ctx, opts.cacheDir, creds, callerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReportCache(opts.cacheDir, opts.cacheTTL, newReportCacheKey(opts, accountID, region))
+}
+
+// cacheAccountID returns the account id of creds: the one the credentials provider resolved, else the one
+// cached in dir for the access key id, else the one callerAccount returns, which is then cached in dir.
+func cacheAccountID(ctx context.Context, dir string, creds aws.Credentials, callerAccount callerAccountFunc) (string, error) {
+	if creds.AccountID != "" {
+		return creds.AccountID, nil
+	}
+
+	sum := sha256.Sum256([]byte(creds.AccessKeyID))
+	path := filepath.Join(dir, CacheAccountsDir, hex.EncodeToString(sum[:]))
+
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		return string(data), nil
+	}
+
+	accountID, err := callerAccount(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the account of the credentials: %w", err)
+	}
+
+	// A mapping that cannot be written only costs another lookup next time.
+	if err := os.MkdirAll(filepath.Dir(path), CacheDirMode); err != nil {
+		log.Printf("Error caching account id: %v", err)
+	} else if err := os.WriteFile(path, []byte(accountID), CacheFileMode); err != nil {
+		log.Printf("Error caching account id: %v", err)
+	}
+
+	return accountID, nil
+}
+
+// Load returns the cached report when it was generated less than the TTL before now. A missing,
+// expired or unreadable cache is a miss; c may be nil.
+func (c *reportCache) Load(now time.Time) (*scanReport, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Error reading cached report: %v", err)
+		}
+
+		return nil, false
+	}
+
+	var report scanReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		log.Printf("Error decoding cached report: %v", err)
+		return nil, false
+	}
+
+	if now.Sub(report.GeneratedAt) >= c.ttl {
+		return nil, false
+	}
+
+	return &report, true
+}
+
+// Store writes report to the cache; c may be nil.
+func (c *reportCache) Store(report *scanReport) error {
+	if c == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), CacheDirMode); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so a concurrent run never reads a partial report.
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, CacheFileMode); err != nil {
+		return fmt.Errorf("failed to write cached report: %w", err)
+	}
+
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write cached report: %w", err)
+	}
+
+	return nil
+}
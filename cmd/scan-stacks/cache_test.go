@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// refusingHTTPClient fails every request and records its host, to assert that no AWS API is called.
+type refusingHTTPClient struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (c *refusingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.hosts = append(c.hosts, req.URL.Host)
+	c.mu.Unlock()
+
+	return nil, errors.New("unexpected AWS API call")
+}
+
+// testCacheConfig returns a configuration with the static credentials of accessKeyID that refuses every API call.
+func testCacheConfig(accessKeyID string, client *refusingHTTPClient) aws.Config {
+	return aws.Config{
+		Region:      testDefaultRegion,
+		Credentials: credentials.NewStaticCredentialsProvider(accessKeyID, "secret", ""),
+		HTTPClient:  client,
+	}
+}
+
+// fakeCallerAccounts resolves the account of each access key id, as STS GetCallerIdentity would, and counts the lookups.
+type fakeCallerAccounts struct {
+	accounts map[string]string
+	lookups  int
+}
+
+// lookup returns the callerAccountFunc of the credentials of cfg.
+func (f *fakeCallerAccounts) lookup(t *testing.T, cfg aws.Config) callerAccountFunc {
+	return func(ctx context.Context) (string, error) {
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		if err != nil {
+			t.Fatalf("Retrieve error: %v", err)
+		}
+
+		f.lookups++
+
+		return f.accounts[creds.AccessKeyID], nil
+	}
+}
+
+func TestCachedReportIsLoadedWithoutAPICalls(t *testing.T) {
+	client := &refusingHTTPClient{}
+	cfg := testCacheConfig("AKIACALLER", client)
+	callers := &fakeCallerAccounts{accounts: map[string]string{"AKIACALLER": testAccount, "AKIAOTHER": testMemberAccount}}
+
+	opts, err := parseOptions("scan-stacks", []string{"-cache-dir", t.TempDir(), "-output", "json"})
+	if err != nil {
+		t.Fatalf("parseOptions error: %v", err)
+	}
+
+	cache, err := openReportCache(context.Background(), cfg, opts, testDefaultRegion, callers.lookup(t, cfg))
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	report := testReport()
+	if err := cache.Store(report); err != nil {
+		t.Fatalf("Store error: %v", err)
+	}
+
+	// A later run opens the cache again and finds the report, knowing the account of the credentials.
+	cache, err = openReportCache(context.Background(), cfg, opts, testDefaultRegion, callers.lookup(t, cfg))
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	cached, ok := cache.Load(testTime.Add(time.Minute))
+	if !ok || len(cached.Regions) != 1 || aws.ToString(cached.Regions[0].Stacks[0].StackName) != "app" {
+		t.Fatalf("Load = %+v, %v, want the stored report", cached, ok)
+	}
+
+	if len(client.hosts) != 0 || callers.lookups != 1 {
+		t.Errorf("a cache hit called AWS APIs at %v and looked up the account %d time(s), want none and once",
+			client.hosts, callers.lookups)
+	}
+
+	if _, ok := cache.Load(testTime.Add(DefaultCacheTTL)); ok {
+		t.Error("Load returned a report older than the TTL")
+	}
+
+	// The credentials of another account miss the cache.
+	otherCfg := testCacheConfig("AKIAOTHER", client)
+
+	other, err := openReportCache(context.Background(), otherCfg, opts, testDefaultRegion, callers.lookup(t, otherCfg))
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	if _, ok := other.Load(testTime.Add(time.Minute)); ok {
+		t.Error("the report cached for AKIACALLER was loaded with the credentials of another account")
+	}
+}
+
+func TestCachedReportIsLoadedWithNewSessionCredentials(t *testing.T) {
+	client := &refusingHTTPClient{}
+	callers := &fakeCallerAccounts{accounts: map[string]string{"ASIASESSION1": testAccount, "ASIASESSION2": testAccount}}
+
+	opts, err := parseOptions("scan-stacks", []string{"-cache-dir", t.TempDir(), "-output", "json"})
+	if err != nil {
+		t.Fatalf("parseOptions error: %v", err)
+	}
+
+	first := testCacheConfig("ASIASESSION1", client)
+
+	cache, err := openReportCache(context.Background(), first, opts, testDefaultRegion, callers.lookup(t, first))
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	if err := cache.Store(testReport()); err != nil {
+		t.Fatalf("Store error: %v", err)
+	}
+
+	// The next run has the session credentials of a new STS or SSO session for the same account.
+	second := testCacheConfig("ASIASESSION2", client)
+
+	cache, err = openReportCache(context.Background(), second, opts, testDefaultRegion, callers.lookup(t, second))
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	if _, ok := cache.Load(testTime.Add(time.Minute)); !ok {
+		t.Error("the report cached with the first session credentials was not loaded with the second")
+	}
+
+	// Credentials that carry their account, e.g. from SSO, need no lookup.
+	third := aws.Config{Credentials: credentials.StaticCredentialsProvider{Value: aws.Credentials{
+		AccessKeyID: "ASIASESSION3", SecretAccessKey: "secret", SessionToken: "token", AccountID: testAccount,
+	}}}
+
+	cache, err = openReportCache(context.Background(), third, opts, testDefaultRegion, func(context.Context) (string, error) {
+		t.Error("looked up the account of credentials that carry it")
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("openReportCache error: %v", err)
+	}
+
+	if _, ok := cache.Load(testTime.Add(time.Minute)); !ok {
+		t.Error("the report cached for the account was not loaded with credentials carrying the account")
+	}
+}
+
+func TestReportCacheKeyChangesWithCollectedOptions(t *testing.T) {
+	key := func(args ...string) reportCacheKey {
+		opts, err := parseOptions("scan-stacks", args)
+		if err != nil {
+			t.Fatalf("parseOptions(%v) error: %v", args, err)
+		}
+
+		return newReportCacheKey(opts, testAccount, testDefaultRegion)
+	}
+
+	if key() != key("-output", "json") {
+		t.Error("-output changed the cache key")
+	}
+
+	for _, args := range [][]string{{"-azs"}, {"-org"}, {"-status-counts", "-output", "json"}, {"-shard", "1/2"}} {
+		if key(args...) == key() {
+			t.Errorf("%v did not change the cache key", args)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// CanonicalNilPlaceholder is the default placeholder for missing values in canonical output.
+const CanonicalNilPlaceholder = "-"
+
+// canonicalWriter writes the canonical text format: regions, stacks and resources sorted, fields in a
+// fixed order and times in UTC, so two scans of the same resources are byte-identical and diff cleanly.
+// The report generation time is left out for the same reason.
+type canonicalWriter struct {
+	w              *bufio.Writer
+	nilPlaceholder string
+}
+
+// canonicalValue returns s on a single line.
+func canonicalValue(s string) string {
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+// field writes an indented "name: value" line.
+func (c canonicalWriter) field(indent string, name string, value string) {
+	fmt.Fprintf(c.w, "%s%s: %s\n", indent, name, canonicalValue(value))
+}
+
+// stringField writes an optional string field.
+func (c canonicalWriter) stringField(indent string, name string, value *string) {
+	c.field(indent, name, nilSafeString(value, c.nilPlaceholder))
+}
+
+// timeField writes an optional time field in UTC.
+func (c canonicalWriter) timeField(indent string, name string, value *time.Time) {
+	if value == nil {
+		c.field(indent, name, c.nilPlaceholder)
+		return
+	}
+
+	c.field(indent, name, value.UTC().Format(time.RFC3339))
+}
+
+// mapField writes a map field with its entries sorted by key, or nothing when it is empty.
+func (c canonicalWriter) mapField(indent string, name string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(c.w, "%s%s:\n", indent, name)
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		c.field(indent+"  ", canonicalValue(key), values[key])
+	}
+}
+
+// writeStack writes a stack and its sorted resources.
+func (c canonicalWriter) writeStack(stack stackReport) {
+	fmt.Fprintf(c.w, "  stack %s\n", canonicalValue(aws.ToString(stack.StackName)))
+	c.stringField("    ", "id", stack.StackID)
+	c.field("    ", "status", stack.StackStatus)
+	c.stringField("    ", "status_reason", stack.StackStatusReason)
+	c.stringField("    ", "parent_id", stack.ParentID)
+	c.stringField("    ", "root_id", stack.RootID)
+	c.stringField("    ", "stack_set", stack.StackSetName)
+	c.timeField("    ", "created", stack.CreationTime)
+	c.timeField("    ", "last_updated", stack.LastUpdatedTime)
+	c.timeField("    ", "deleted", stack.DeletionTime)
+	c.mapField("    ", "tags", stack.Tags)
+
+	if len(stack.ResourceStatusCounts) > 0 {
+		counts := make(map[string]string, len(stack.ResourceStatusCounts))
+		for status, count := range stack.ResourceStatusCounts {
+			counts[status] = fmt.Sprint(count)
+		}
+
+		c.mapField("    ", "resource_status_counts", counts)
+	}
+
+	resources := slices.SortedFunc(slices.Values(stack.Resources), func(a, b resourceReport) int {
+		return cmp.Or(
+			cmp.Compare(aws.ToString(a.LogicalResourceID), aws.ToString(b.LogicalResourceID)),
+			cmp.Compare(aws.ToString(a.PhysicalResourceID), aws.ToString(b.PhysicalResourceID)),
+		)
+	})
+
+	for _, resource := range resources {
+		fmt.Fprintf(c.w, "    resource %s\n", canonicalValue(aws.ToString(resource.LogicalResourceID)))
+		c.stringField("      ", "physical_id", resource.PhysicalResourceID)
+		c.stringField("      ", "type", resource.ResourceType)
+		c.field("      ", "status", resource.ResourceStatus)
+		c.stringField("      ", "status_reason", resource.ResourceStatusReason)
+		c.timeField("      ", "last_updated", resource.LastUpdatedTimestamp)
+
+		if resource.Timeline != nil {
+			c.timeField("      ", "timeline_created", resource.Timeline.Created)
+			c.timeField("      ", "timeline_last_updated", resource.Timeline.LastUpdated)
+			c.field("      ", "timeline_updates", fmt.Sprint(resource.Timeline.Updates))
+		}
+	}
+}
+
+// writeCanonicalReport writes report in the canonical text format.
+func (f formatter) writeCanonicalReport(w io.Writer, report *scanReport) error {
+	c := canonicalWriter{w: bufio.NewWriter(w), nilPlaceholder: f.nilPlaceholder}
+
+	regions := slices.SortedFunc(slices.Values(report.Regions), func(a, b regionReport) int {
+		return cmp.Or(cmp.Compare(a.Account, b.Account), cmp.Compare(a.Region, b.Region))
+	})
+
+	for _, region := range regions {
+		fmt.Fprintf(c.w, "region %s %s\n", region.Account, region.Region)
+
+		if len(region.AvailabilityZones) > 0 {
+			c.field("  ", "availability_zones", strings.Join(slices.Sorted(slices.Values(region.AvailabilityZones)), " "))
+		}
+
+		stacks := slices.SortedFunc(slices.Values(region.Stacks), func(a, b stackReport) int {
+			return cmp.Or(
+				cmp.Compare(aws.ToString(a.StackName), aws.ToString(b.StackName)),
+				cmp.Compare(aws.ToString(a.StackID), aws.ToString(b.StackID)),
+			)
+		})
+
+		for _, stack := range stacks {
+			c.writeStack(stack)
+		}
+	}
+
+	if len(report.RegionsWithoutStacks) > 0 {
+		c.field("", "regions_without_stacks", strings.Join(report.RegionsWithoutStacks, " "))
+	}
+
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write canonical report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// canonicalAccount returns the same account for every call, listed in reverse order when reversed and
+// with its times in loc, as two scans of unchanged resources may return them.
+func canonicalAccount(reversed bool, loc *time.Location) *fakeAccount {
+	updated := aws.Time(testTime.In(loc))
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	web.CreationTime = updated
+
+	api := testStack(testDefaultRegion, "api", cfTypes.StackStatusUpdateRollbackComplete)
+	api.StackStatusReason = aws.String("Resource Function failed:\nrate exceeded")
+
+	queue := testStack("us-east-1", "queue", cfTypes.StackStatusUpdateComplete)
+
+	bucket := testResource("Bucket", "AWS::S3::Bucket", "web-assets")
+	bucket.LastUpdatedTimestamp = updated
+
+	function := testResource("Function", "AWS::Lambda::Function", "api-handler")
+	function.ResourceStatus = cfTypes.ResourceStatusUpdateFailed
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "us-east-1"},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, api}, "us-east-1": {queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId:   {bucket, testResource("Role", "AWS::IAM::Role", "web-role")},
+			*api.StackId:   {function, testResource("Api", "AWS::ApiGateway::RestApi", "")},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "jobs")},
+		},
+	}
+
+	if reversed {
+		slices.Reverse(account.regions)
+
+		for _, stacks := range account.stacks {
+			slices.Reverse(stacks)
+		}
+
+		for _, resources := range account.resources {
+			slices.Reverse(resources)
+		}
+	}
+
+	return account
+}
+
+// scanCanonical scans account and returns its canonical report.
+func scanCanonical(t *testing.T, account *fakeAccount) []byte {
+	t.Helper()
+
+	s := newTestScanner(t, account, false, "-output", "canonical", "-status-counts")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	// Scans run at different times.
+	s.report.GeneratedAt = time.Now()
+
+	var buf bytes.Buffer
+	if err := s.format.writeCanonicalReport(&buf, s.report); err != nil {
+		t.Fatalf("writeCanonicalReport error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCanonicalReportIsByteIdentical(t *testing.T) {
+	captureLog(t)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	first := scanCanonical(t, canonicalAccount(false, time.UTC))
+	second := scanCanonical(t, canonicalAccount(true, tokyo))
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("canonical reports of the same resources differ:\n%s\n---\n%s", first, second)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "canonical.txt"))
+	if err != nil {
+		t.Fatalf("reading expected canonical report: %v", err)
+	}
+
+	if !bytes.Equal(first, want) {
+		t.Errorf("canonical report =\n%s\nwant\n%s", first, want)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"strings"
+)
+
+// regionCoverageKey identifies a scanned region of an account in the coverage notes.
+func regionCoverageKey(account string, region string) string {
+	return account + "/" + region
+}
+
+// parseRegionCoverageKey splits a key of regionCoverageKey into its account and region.
+func parseRegionCoverageKey(key string) (string, string, bool) {
+	return strings.Cut(key, "/")
+}
+
+// omitEmptyRegions returns a copy of report without the regions that have no stacks, which are noted
+// in RegionsWithoutStacks instead, so the scan coverage stays visible.
+func omitEmptyRegions(report *scanReport) *scanReport {
+	omitted := &scanReport{GeneratedAt: report.GeneratedAt, Regions: []regionReport{}}
+
+	for _, region := range report.Regions {
+		if len(region.Stacks) == 0 {
+			omitted.RegionsWithoutStacks = append(omitted.RegionsWithoutStacks, regionCoverageKey(region.Account, region.Region))
+			continue
+		}
+
+		omitted.Regions = append(omitted.Regions, region)
+	}
+
+	slices.Sort(omitted.RegionsWithoutStacks)
+
+	return omitted
+}
+
+// printRegionsWithoutStacks logs the scanned regions that were left out of the text output for having no stacks.
+func printRegionsWithoutStacks(regions []string) {
+	regions = slices.Sorted(slices.Values(regions))
+
+	log.Printf("Regions without stacks (scanned, not shown): %d\n", len(regions))
+
+	for _, region := range regions {
+		log.Printf("- %s", region)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// coverageAccount has stacks only in the default region of its three regions.
+func coverageAccount() *fakeAccount {
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+
+	return &fakeAccount{
+		regions:   []string{testDefaultRegion, "us-east-1", "eu-west-1"},
+		stacks:    map[string][]cfTypes.StackSummary{testDefaultRegion: {web}},
+		resources: map[string][]cfTypes.StackResourceSummary{*web.StackId: {testResource("Bucket", "AWS::S3::Bucket", "web-assets")}},
+	}
+}
+
+func TestOnlyRegionsWithStacksOmitsAndRecordsEmptyRegions(t *testing.T) {
+	captureLog(t)
+
+	s := newTestScanner(t, coverageAccount(), false, "-only-regions-with-stacks", "-output", "csv")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	output := omitEmptyRegions(s.report)
+
+	if len(output.Regions) != 1 || output.Regions[0].Region != testDefaultRegion {
+		t.Errorf("regions = %+v, want only %s", output.Regions, testDefaultRegion)
+	}
+
+	empty := []string{testAccount + "/eu-west-1", testAccount + "/us-east-1"}
+	if !slices.Equal(output.RegionsWithoutStacks, empty) {
+		t.Errorf("regions without stacks = %v, want %v", output.RegionsWithoutStacks, empty)
+	}
+
+	var buf bytes.Buffer
+	if err := s.format.writeReport(&buf, output); err != nil {
+		t.Fatalf("writeReport error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV report: %v", err)
+	}
+
+	var got []string
+	for _, row := range rows[1:] {
+		got = append(got, strings.Join(row[:4], ","))
+	}
+
+	want := []string{
+		testAccount + ",us-west-2," + "arn:aws:cloudformation:us-west-2:" + testAccount + ":stack/web/id,web",
+		testAccount + ",eu-west-1,,",
+		testAccount + ",us-east-1,,",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("CSV rows =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestOnlyRegionsWithStacksInTextOutput(t *testing.T) {
+	logs := captureLog(t)
+
+	s := newTestScanner(t, coverageAccount(), true, "-only-regions-with-stacks", "-concurrency", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	printRegionsWithoutStacks(s.emptyRegions)
+
+	if strings.Contains(logs.String(), "- Region: us-east-1") || !strings.Contains(logs.String(), "- Region: us-west-2") {
+		t.Errorf("text output does not show only the region with stacks:\n%s", logs)
+	}
+
+	if want := "Regions without stacks (scanned, not shown): 2\n- " + testAccount + "/eu-west-1\n- " + testAccount + "/us-east-1\n"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// AnonymousCredentialsSource is reported when no credentials provider is configured.
+const AnonymousCredentialsSource = "anonymous"
+
+// credentialsSource returns the name of the provider that resolved the credentials of provider, such as
+// EnvConfigCredentials, SharedConfigCredentials, AssumeRoleProvider or SSOProvider. Only the name is
+// returned; the credentials themselves are never exposed.
+func credentialsSource(ctx context.Context, provider aws.CredentialsProvider) (string, error) {
+	if provider == nil {
+		return AnonymousCredentialsSource, nil
+	}
+
+	creds, err := provider.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+
+	if creds.Source == "" {
+		return fmt.Sprintf("%T", provider), nil
+	}
+
+	return creds.Source, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// namedCredentialsProvider returns credentials resolved by the provider named source, or err.
+type namedCredentialsProvider struct {
+	source string
+	err    error
+}
+
+func (p namedCredentialsProvider) Retrieve(_ context.Context) (aws.Credentials, error) {
+	if p.err != nil {
+		return aws.Credentials{}, p.err
+	}
+
+	return aws.Credentials{AccessKeyID: "AKIASECRET", SecretAccessKey: "secret", Source: p.source}, nil
+}
+
+func TestCredentialsSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider aws.CredentialsProvider
+		want     string
+	}{
+		{"nil", nil, AnonymousCredentialsSource},
+		{"static", credentials.NewStaticCredentialsProvider("AKIASECRET", "secret", ""), credentials.StaticCredentialsName},
+		{"named", namedCredentialsProvider{source: "SSOProvider"}, "SSOProvider"},
+		{"cached", aws.NewCredentialsCache(namedCredentialsProvider{source: "AssumeRoleProvider"}), "AssumeRoleProvider"},
+		{"unnamed", namedCredentialsProvider{}, "main.namedCredentialsProvider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := credentialsSource(context.Background(), tt.provider)
+			if err != nil || got != tt.want {
+				t.Errorf("credentialsSource = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := credentialsSource(context.Background(), namedCredentialsProvider{err: errors.New("no credentials")}); err == nil {
+		t.Error("credentialsSource returned no error for a failing provider")
+	}
+}
+
+func TestShowCredsSourceLogsInjectedProvider(t *testing.T) {
+	logs := captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)}},
+	}
+	s := newTestScanner(t, account, true, "-show-creds-source")
+
+	target := scanTarget{AccountID: testAccount, Config: aws.Config{Credentials: namedCredentialsProvider{source: "SSOProvider"}}}
+	if err := s.scanAccount(context.Background(), target, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	if want := "  - Credentials Source: SSOProvider (account " + testAccount + ")\n"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+
+	if strings.Contains(logs.String(), "AKIASECRET") {
+		t.Errorf("log contains the access key id:\n%s", logs)
+	}
+}
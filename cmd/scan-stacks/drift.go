@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// staleDriftStack describes a stack whose drift detection results are missing or out of date.
+type staleDriftStack struct {
+	Region    string
+	StackName string
+	StackID   string
+	LastCheck *time.Time
+}
+
+// isDriftCheckStale reports whether the stack has never been checked for drift,
+// or whether its last drift check happened more than threshold before now.
+func isDriftCheckStale(stack cfTypes.StackSummary, threshold time.Duration, now time.Time) bool {
+	drift := stack.DriftInformation
+	if drift == nil || drift.LastCheckTimestamp == nil || drift.StackDriftStatus == cfTypes.StackDriftStatusNotChecked {
+		return true
+	}
+
+	return now.Sub(*drift.LastCheckTimestamp) > threshold
+}
+
+// findStaleDriftStacks returns the stacks in region whose drift check is stale.
+func findStaleDriftStacks(region string, stacks []cfTypes.StackSummary, threshold time.Duration, now time.Time) []staleDriftStack {
+	var stale []staleDriftStack
+
+	for _, stack := range stacks {
+		if !isDriftCheckStale(stack, threshold, now) {
+			continue
+		}
+
+		entry := staleDriftStack{
+			Region:    region,
+			StackName: NilSafeString(stack.StackName),
+			StackID:   NilSafeString(stack.StackId),
+		}
+
+		if stack.DriftInformation != nil {
+			entry.LastCheck = stack.DriftInformation.LastCheckTimestamp
+		}
+
+		stale = append(stale, entry)
+	}
+
+	return stale
+}
+
+// printStaleDriftReport logs the stacks that need a drift detection run.
+func printStaleDriftReport(stale []staleDriftStack, threshold time.Duration) {
+	log.Printf("Stacks with drift checks older than %s or never checked: %d\n", threshold, len(stale))
+
+	for _, entry := range stale {
+		lastCheck := "never"
+		if entry.LastCheck != nil {
+			lastCheck = NilSafeTime(entry.LastCheck, "")
+		}
+
+		log.Printf("- %s (%s) last drift check: %s", entry.StackName, entry.Region, lastCheck)
+		log.Printf("  - Id: %s", entry.StackID)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// testDriftStack returns a stack of region whose drift was last checked at lastCheck (nil for never).
+func testDriftStack(region string, name string, status cfTypes.StackDriftStatus, lastCheck *time.Time) cfTypes.StackSummary {
+	stack := testStack(region, name, cfTypes.StackStatusUpdateComplete)
+	stack.DriftInformation = &cfTypes.StackDriftInformationSummary{StackDriftStatus: status, LastCheckTimestamp: lastCheck}
+
+	return stack
+}
+
+func TestIsDriftCheckStale(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	threshold := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		stack cfTypes.StackSummary
+		want  bool
+	}{
+		{"no drift information", testStack(testDefaultRegion, "a", cfTypes.StackStatusCreateComplete), true},
+		{"never checked", testDriftStack(testDefaultRegion, "a", cfTypes.StackDriftStatusNotChecked, nil), true},
+		{"no timestamp", testDriftStack(testDefaultRegion, "a", cfTypes.StackDriftStatusInSync, nil), true},
+		{"old check", testDriftStack(testDefaultRegion, "a", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-threshold-time.Hour))), true},
+		{"recent check", testDriftStack(testDefaultRegion, "a", cfTypes.StackDriftStatusDrifted, aws.Time(now.Add(-time.Hour))), false},
+		{"check at threshold", testDriftStack(testDefaultRegion, "a", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-threshold))), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDriftCheckStale(tt.stack, threshold, now); got != tt.want {
+				t.Errorf("isDriftCheckStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStaleDriftStacksFlagsOldAndAbsentChecks(t *testing.T) {
+	now := time.Now()
+	stacks := []cfTypes.StackSummary{
+		testStack(testDefaultRegion, "never", cfTypes.StackStatusCreateComplete),
+		testDriftStack(testDefaultRegion, "not-checked", cfTypes.StackDriftStatusNotChecked, nil),
+		testDriftStack(testDefaultRegion, "old", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-30*24*time.Hour))),
+		testDriftStack(testDefaultRegion, "fresh", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-time.Hour))),
+	}
+
+	var stale []string
+	for _, entry := range findStaleDriftStacks(testDefaultRegion, stacks, 168*time.Hour, now) {
+		stale = append(stale, entry.Region+"/"+entry.StackName)
+	}
+
+	want := []string{testDefaultRegion + "/never", testDefaultRegion + "/not-checked", testDefaultRegion + "/old"}
+	if !slices.Equal(stale, want) {
+		t.Errorf("stale drift stacks = %v, want %v", stale, want)
+	}
+}
+
+func TestDriftStaleFlagsOldAndAbsentChecks(t *testing.T) {
+	captureLog(t)
+
+	now := time.Now()
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "us-east-1"},
+		stacks: map[string][]cfTypes.StackSummary{
+			testDefaultRegion: {
+				testStack(testDefaultRegion, "never", cfTypes.StackStatusCreateComplete),
+				testDriftStack(testDefaultRegion, "not-checked", cfTypes.StackDriftStatusNotChecked, nil),
+				testDriftStack(testDefaultRegion, "old", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-30*24*time.Hour))),
+				testDriftStack(testDefaultRegion, "fresh", cfTypes.StackDriftStatusInSync, aws.Time(now.Add(-time.Hour))),
+			},
+			"us-east-1": {
+				testDriftStack("us-east-1", "fresh-east", cfTypes.StackDriftStatusDrifted, aws.Time(now.Add(-time.Hour))),
+			},
+		},
+	}
+	s := newTestScanner(t, account, false, "-drift-stale", "168h")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	var stale []string
+	for _, entry := range s.staleDrift {
+		stale = append(stale, entry.Region+"/"+entry.StackName)
+	}
+
+	slices.Sort(stale)
+
+	want := []string{testDefaultRegion + "/never", testDefaultRegion + "/not-checked", testDefaultRegion + "/old"}
+	if !slices.Equal(stale, want) {
+		t.Errorf("stale drift stacks = %v, want %v", stale, want)
+	}
+}
+
+func TestParseOptionsDriftStale(t *testing.T) {
+	opts, err := parseOptions("scan-stacks", []string{"-drift-stale", "48h"})
+	if err != nil {
+		t.Fatalf("parseOptions error: %v", err)
+	}
+
+	if opts.driftStale != 48*time.Hour {
+		t.Errorf("driftStale = %s, want 48h", opts.driftStale)
+	}
+
+	if _, err := parseOptions("scan-stacks", []string{"-drift-stale", "-1h"}); err == nil {
+		t.Error("parseOptions accepted a negative -drift-stale")
+	}
+}
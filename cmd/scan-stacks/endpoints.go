@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// cloudFormationEndpoint returns the endpoint the CloudFormation client built from cfg sends requests
+// to, taking endpoint overrides and the FIPS and dual-stack settings into account.
+func cloudFormationEndpoint(ctx context.Context, cfg aws.Config) (string, error) {
+	options := cloudformation.NewFromConfig(cfg).Options()
+
+	endpoint, err := options.EndpointResolverV2.ResolveEndpoint(ctx, cloudformation.EndpointParameters{
+		Region:       aws.String(options.Region),
+		Endpoint:     options.BaseEndpoint,
+		UseFIPS:      aws.Bool(options.EndpointOptions.UseFIPSEndpoint == aws.FIPSEndpointStateEnabled),
+		UseDualStack: aws.Bool(options.EndpointOptions.UseDualStackEndpoint == aws.DualStackEndpointStateEnabled),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve CloudFormation endpoint: %w", err)
+	}
+
+	return endpoint.URI.String(), nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// loadTestConfig loads the AWS configuration of region with optFns, isolated from the environment and
+// shared config files of the machine running the tests.
+func loadTestConfig(t *testing.T, region string, optFns ...func(*config.LoadOptions) error) aws.Config {
+	t.Helper()
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("AWS_CONFIG_FILE", missing)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", missing)
+	t.Setenv("AWS_USE_FIPS_ENDPOINT", "")
+	t.Setenv("AWS_USE_DUALSTACK_ENDPOINT", "")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("AWS_ENDPOINT_URL_CLOUDFORMATION", "")
+
+	optFns = append([]func(*config.LoadOptions) error{
+		config.WithRegion(region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("AKIATEST", "secret", "")),
+	}, optFns...)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), optFns...)
+	if err != nil {
+		t.Fatalf("LoadDefaultConfig error: %v", err)
+	}
+
+	return cfg
+}
+
+func TestCloudFormationEndpointWithAndWithoutFIPS(t *testing.T) {
+	tests := []struct {
+		name   string
+		optFns []func(*config.LoadOptions) error
+		want   string
+	}{
+		{"default", nil, "https://cloudformation.us-west-2.amazonaws.com"},
+		{
+			"fips",
+			[]func(*config.LoadOptions) error{config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled)},
+			"https://cloudformation-fips.us-west-2.amazonaws.com",
+		},
+		{
+			"fips disabled",
+			[]func(*config.LoadOptions) error{config.WithUseFIPSEndpoint(aws.FIPSEndpointStateDisabled)},
+			"https://cloudformation.us-west-2.amazonaws.com",
+		},
+		{
+			"override",
+			[]func(*config.LoadOptions) error{config.WithBaseEndpoint("http://localhost:4566")},
+			"http://localhost:4566",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cloudFormationEndpoint(context.Background(), loadTestConfig(t, testDefaultRegion, tt.optFns...))
+			if err != nil || got != tt.want {
+				t.Errorf("cloudFormationEndpoint = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowEndpointsLogsEachRegion(t *testing.T) {
+	logs := captureLog(t)
+
+	cfg := loadTestConfig(t, testDefaultRegion, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+
+	s := newTestScanner(t, &fakeAccount{regions: []string{testDefaultRegion, "us-east-1"}}, false, "-show-endpoints")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount, Config: cfg}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	for _, line := range []string{
+		"CloudFormation endpoint for region 'us-west-2': https://cloudformation-fips.us-west-2.amazonaws.com\n",
+		"CloudFormation endpoint for region 'us-east-1': https://cloudformation-fips.us-east-1.amazonaws.com\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+}
+
+func TestDualStackStateOfConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		optFns []func(*config.LoadOptions) error
+		want   string
+	}{
+		{"ipv4", nil, nil, "https://cloudformation.us-west-2.amazonaws.com"},
+		{"dualstack", []string{"-dualstack"}, nil, "https://cloudformation.us-west-2.api.aws"},
+		{
+			"dualstack with fips",
+			[]string{"-dualstack"},
+			[]func(*config.LoadOptions) error{config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled)},
+			"https://cloudformation-fips.us-west-2.api.aws",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptions("scan-stacks", tt.args)
+			if err != nil {
+				t.Fatalf("parseOptions(%v) error: %v", tt.args, err)
+			}
+
+			cfg := loadTestConfig(t, testDefaultRegion, append(awsConfigOptions(opts, testDefaultRegion), tt.optFns...)...)
+
+			// Member accounts of -org get the dual-stack state of the caller's configuration.
+			target := newRoleScanTarget(testMemberAccount, cfg, "arn:aws:iam::"+testMemberAccount+":role/"+DefaultOrgRole)
+
+			for _, regionCfg := range []aws.Config{cfg, target.ConfigForRegion(testDefaultRegion)} {
+				got, err := cloudFormationEndpoint(context.Background(), regionCfg)
+				if err != nil || got != tt.want {
+					t.Errorf("cloudFormationEndpoint = %q, %v, want %q", got, err, tt.want)
+				}
+			}
+		})
+	}
+}
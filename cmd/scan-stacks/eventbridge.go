@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+const (
+	// EventSource is the EventBridge source of every event emitted by scan-stacks.
+	EventSource = "aws-go-tools.scan-stacks"
+	// EventDetailType is the EventBridge detail-type of stack finding events.
+	EventDetailType = "CloudFormation Stack Finding"
+	// MaxPutEventsEntries is the maximum number of entries EventBridge accepts per PutEvents call.
+	MaxPutEventsEntries = 10
+)
+
+// eventBridgeAPI is the subset of the EventBridge client used by scan-stacks.
+type eventBridgeAPI interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// newFindingEventEntry builds the EventBridge entry describing finding.
+func newFindingEventEntry(eventBusName string, finding stackFinding) (ebTypes.PutEventsRequestEntry, error) {
+	detail, err := json.Marshal(finding)
+	if err != nil {
+		return ebTypes.PutEventsRequestEntry{}, fmt.Errorf("failed to marshal finding detail: %w", err)
+	}
+
+	return ebTypes.PutEventsRequestEntry{
+		EventBusName: aws.String(eventBusName),
+		Source:       aws.String(EventSource),
+		DetailType:   aws.String(EventDetailType),
+		Detail:       aws.String(string(detail)),
+		Resources:    []string{finding.StackID},
+	}, nil
+}
+
+// putFindingEvents sends one event per finding to the eventBusName bus, in batches PutEvents accepts.
+func putFindingEvents(ctx context.Context, client eventBridgeAPI, eventBusName string, findings []stackFinding) error {
+	entries := make([]ebTypes.PutEventsRequestEntry, 0, len(findings))
+
+	for _, finding := range findings {
+		entry, err := newFindingEventEntry(eventBusName, finding)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	for start := 0; start < len(entries); start += MaxPutEventsEntries {
+		end := min(start+MaxPutEventsEntries, len(entries))
+
+		output, err := client.PutEvents(ctx, &eventbridge.PutEventsInput{Entries: entries[start:end]})
+		if err != nil {
+			return fmt.Errorf("failed to put events: %w", err)
+		}
+
+		if output.FailedEntryCount > 0 {
+			return fmt.Errorf("failed to put %d of %d events", output.FailedEntryCount, end-start)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+)
+
+// fakeEventBridge records the entries of every PutEvents call.
+type fakeEventBridge struct {
+	calls []*eventbridge.PutEventsInput
+}
+
+func (f *fakeEventBridge) PutEvents(_ context.Context, params *eventbridge.PutEventsInput, _ ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	f.calls = append(f.calls, params)
+
+	return &eventbridge.PutEventsOutput{}, nil
+}
+
+func TestPutFindingEventsFailedStack(t *testing.T) {
+	failed := testStack(testDefaultRegion, "broken", cfTypes.StackStatusUpdateRollbackFailed)
+	failed.StackStatusReason = aws.String("Resource Bucket failed to update")
+
+	stacks := []cfTypes.StackSummary{failed, testStack(testDefaultRegion, "healthy", cfTypes.StackStatusCreateComplete)}
+	findings := findStackFindings(testAccount, testDefaultRegion, stacks)
+
+	client := &fakeEventBridge{}
+	if err := putFindingEvents(context.Background(), client, "ops-bus", findings); err != nil {
+		t.Fatalf("putFindingEvents error: %v", err)
+	}
+
+	if len(client.calls) != 1 || len(client.calls[0].Entries) != 1 {
+		t.Fatalf("PutEvents calls = %v, want one call with one entry", client.calls)
+	}
+
+	entry := client.calls[0].Entries[0]
+	if got := aws.ToString(entry.EventBusName); got != "ops-bus" {
+		t.Errorf("EventBusName = %q, want ops-bus", got)
+	}
+
+	if got := aws.ToString(entry.Source); got != EventSource {
+		t.Errorf("Source = %q, want %q", got, EventSource)
+	}
+
+	if got := aws.ToString(entry.DetailType); got != EventDetailType {
+		t.Errorf("DetailType = %q, want %q", got, EventDetailType)
+	}
+
+	if len(entry.Resources) != 1 || entry.Resources[0] != aws.ToString(failed.StackId) {
+		t.Errorf("Resources = %v, want [%s]", entry.Resources, aws.ToString(failed.StackId))
+	}
+
+	var detail stackFinding
+	if err := json.Unmarshal([]byte(aws.ToString(entry.Detail)), &detail); err != nil {
+		t.Fatalf("Detail is not a JSON finding: %v", err)
+	}
+
+	want := stackFinding{
+		FindingType:       FindingTypeFailed,
+		Account:           testAccount,
+		Region:            testDefaultRegion,
+		StackName:         "broken",
+		StackID:           aws.ToString(failed.StackId),
+		StackStatus:       string(cfTypes.StackStatusUpdateRollbackFailed),
+		StackStatusReason: "Resource Bucket failed to update",
+	}
+	if detail != want {
+		t.Errorf("Detail = %+v, want %+v", detail, want)
+	}
+}
+
+func TestPutFindingEventsBatches(t *testing.T) {
+	findings := make([]stackFinding, MaxPutEventsEntries+1)
+	for i := range findings {
+		findings[i] = stackFinding{FindingType: FindingTypeDrifted, StackID: "stack"}
+	}
+
+	client := &fakeEventBridge{}
+	if err := putFindingEvents(context.Background(), client, "ops-bus", findings); err != nil {
+		t.Fatalf("putFindingEvents error: %v", err)
+	}
+
+	if len(client.calls) != 2 || len(client.calls[0].Entries) != MaxPutEventsEntries || len(client.calls[1].Entries) != 1 {
+		t.Errorf("PutEvents batches = %d, want %d entries then 1", len(client.calls), MaxPutEventsEntries)
+	}
+}
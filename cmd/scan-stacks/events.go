@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// Scan event types emitted with -emit-events.
+const (
+	EventRegionStarted = "region-started"
+	EventStackFound    = "stack-found"
+	EventResourceFound = "resource-found"
+	EventRegionDone    = "region-done"
+
+	// EventLengthPrefixSize is the size in bytes of the length prefix of every event message.
+	EventLengthPrefixSize = 4
+)
+
+// scanEvent is a single message of the -emit-events stream.
+type scanEvent struct {
+	Type     string         `json:"type"`
+	Account  string         `json:"account,omitempty"`
+	Region   string         `json:"region"`
+	Stack    *eventStack    `json:"stack,omitempty"`
+	Resource *eventResource `json:"resource,omitempty"`
+	// StackCount is the number of stacks found in the region, set on region-done events.
+	StackCount int `json:"stackCount,omitempty"`
+}
+
+// eventStack describes the stack of a stack-found event.
+type eventStack struct {
+	StackID     string `json:"stackId"`
+	StackName   string `json:"stackName"`
+	StackStatus string `json:"stackStatus"`
+}
+
+// eventResource describes the resource of a resource-found event.
+type eventResource struct {
+	StackName          string `json:"stackName"`
+	LogicalResourceID  string `json:"logicalResourceId"`
+	PhysicalResourceID string `json:"physicalResourceId,omitempty"`
+	ResourceType       string `json:"resourceType"`
+	ResourceStatus     string `json:"resourceStatus"`
+}
+
+// eventEmitter writes scan events as length-prefixed JSON messages: a 4 byte big-endian
+// length followed by that many bytes of JSON, so a frontend can consume them incrementally.
+// A nil emitter discards events.
+type eventEmitter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newEventEmitter returns an emitter writing to w.
+func newEventEmitter(w io.Writer) *eventEmitter {
+	return &eventEmitter{w: w}
+}
+
+// Emit writes event to the stream.
+func (e *eventEmitter) Emit(event scanEvent) error {
+	if e == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+	}
+
+	if len(payload) > math.MaxUint32 {
+		return fmt.Errorf("%s event too large: %d bytes", event.Type, len(payload))
+	}
+
+	frame := make([]byte, EventLengthPrefixSize, EventLengthPrefixSize+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.w.Write(frame); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", event.Type, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// readScanEvents decodes every length-prefixed event of stream.
+func readScanEvents(t *testing.T, stream io.Reader) []scanEvent {
+	t.Helper()
+
+	var events []scanEvent
+
+	for {
+		prefix := make([]byte, EventLengthPrefixSize)
+		if _, err := io.ReadFull(stream, prefix); errors.Is(err, io.EOF) {
+			return events
+		} else if err != nil {
+			t.Fatalf("reading event length: %v", err)
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(prefix))
+		if _, err := io.ReadFull(stream, payload); err != nil {
+			t.Fatalf("reading event payload: %v", err)
+		}
+
+		var event scanEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("decoding event %q: %v", payload, err)
+		}
+
+		events = append(events, event)
+	}
+}
+
+func TestEmitEventsSequence(t *testing.T) {
+	captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	queue := testStack(testDefaultRegion, "queue", cfTypes.StackStatusUpdateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "us-east-1"},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				testResource("Role", "AWS::IAM::Role", "web-role"),
+			},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "jobs")},
+		},
+	}
+
+	var stream bytes.Buffer
+
+	s := newTestScanner(t, account, false, "-emit-events", "events.bin", "-concurrency", "1")
+	s.emitter = newEventEmitter(&stream)
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	sequences := map[string][]string{}
+
+	for _, event := range readScanEvents(t, &stream) {
+		if event.Account != testAccount {
+			t.Errorf("event %s has account %q, want %s", event.Type, event.Account, testAccount)
+		}
+
+		step := event.Type
+		switch {
+		case event.Stack != nil:
+			step += " " + event.Stack.StackName + " " + event.Stack.StackStatus
+		case event.Resource != nil:
+			step += " " + event.Resource.StackName + "/" + event.Resource.LogicalResourceID
+		case event.Type == EventRegionDone:
+			step += " " + strings.Repeat("*", event.StackCount)
+		}
+
+		sequences[event.Region] = append(sequences[event.Region], step)
+	}
+
+	want := map[string][]string{
+		testDefaultRegion: {
+			"region-started",
+			"stack-found web CREATE_COMPLETE",
+			"stack-found queue UPDATE_COMPLETE",
+			"resource-found web/Bucket",
+			"resource-found web/Role",
+			"resource-found queue/Queue",
+			"region-done **",
+		},
+		"us-east-1": {"region-started", "region-done "},
+	}
+
+	for region, steps := range want {
+		if !slices.Equal(sequences[region], steps) {
+			t.Errorf("%s events =\n%q\nwant\n%q", region, sequences[region], steps)
+		}
+	}
+}
+
+func TestEmitEventsToStdoutRejectsOtherStdoutOutput(t *testing.T) {
+	if _, err := parseOptions("scan-stacks", []string{"-emit-events", "-"}); err != nil {
+		t.Errorf("-emit-events - with text output: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"-output", "json"},
+		{"-output", "canonical"},
+		{"-output-template-per-resource", "{{.StackName}}"},
+		{"-manifest", "-"},
+		{"-service-map"},
+		{"-stack", "app", "-outputs-as-env"},
+	} {
+		if _, err := parseOptions("scan-stacks", append([]string{"-emit-events", "-"}, args...)); err == nil {
+			t.Errorf("-emit-events - accepted with %v", args)
+		}
+
+		if _, err := parseOptions("scan-stacks", append([]string{"-emit-events", "events.bin"}, args...)); err != nil {
+			t.Errorf("-emit-events to a file rejected with %v: %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import "fmt"
+
+// statusExplanations explains the CloudFormation stack and resource statuses in plain words.
+var statusExplanations = map[string]string{
+	"CREATE_IN_PROGRESS":                           "being created",
+	"CREATE_FAILED":                                "creation failed; see the status reason",
+	"CREATE_COMPLETE":                              "created successfully",
+	"ROLLBACK_IN_PROGRESS":                         "creation failed and the created resources are being removed",
+	"ROLLBACK_FAILED":                              "creation failed and removing the created resources also failed; delete the stack",
+	"ROLLBACK_COMPLETE":                            "creation failed and the created resources were removed; the stack can only be deleted",
+	"DELETE_IN_PROGRESS":                           "being deleted",
+	"DELETE_FAILED":                                "deletion failed; some resources may still exist",
+	"DELETE_COMPLETE":                              "deleted",
+	"DELETE_SKIPPED":                               "kept on deletion because of its deletion policy",
+	"UPDATE_IN_PROGRESS":                           "being updated",
+	"UPDATE_FAILED":                                "update failed; see the status reason",
+	"UPDATE_COMPLETE_CLEANUP_IN_PROGRESS":          "update succeeded and replaced resources are being removed",
+	"UPDATE_COMPLETE":                              "updated successfully",
+	"UPDATE_ROLLBACK_IN_PROGRESS":                  "update failed and the previous configuration is being restored",
+	"UPDATE_ROLLBACK_FAILED":                       "update failed and restoring the previous configuration also failed; continue the rollback",
+	"UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS": "previous configuration restored and resources created by the failed update are being removed",
+	"UPDATE_ROLLBACK_COMPLETE":                     "update failed and the previous configuration was restored",
+	"REVIEW_IN_PROGRESS":                           "created by a change set that has not been executed yet",
+	"IMPORT_IN_PROGRESS":                           "existing resources are being imported",
+	"IMPORT_COMPLETE":                              "existing resources were imported",
+	"IMPORT_FAILED":                                "importing the resource failed",
+	"IMPORT_ROLLBACK_IN_PROGRESS":                  "import failed and the previous configuration is being restored",
+	"IMPORT_ROLLBACK_FAILED":                       "import failed and restoring the previous configuration also failed",
+	"IMPORT_ROLLBACK_COMPLETE":                     "import failed and the previous configuration was restored",
+	"EXPORT_IN_PROGRESS":                           "being exported",
+	"EXPORT_FAILED":                                "export failed",
+	"EXPORT_COMPLETE":                              "exported",
+	"EXPORT_ROLLBACK_IN_PROGRESS":                  "export failed and is being rolled back",
+	"EXPORT_ROLLBACK_FAILED":                       "export failed and rolling it back also failed",
+	"EXPORT_ROLLBACK_COMPLETE":                     "export failed and was rolled back",
+}
+
+// explainStatus returns the plain words explanation of a CloudFormation status, or "" when it is unknown.
+func explainStatus(status string) string {
+	return statusExplanations[status]
+}
+
+// annotateStatus returns status followed by its explanation in parentheses, or status alone when it is unknown.
+func annotateStatus(status string) string {
+	explanation := explainStatus(status)
+	if explanation == "" {
+		return status
+	}
+
+	return fmt.Sprintf("%s (%s)", status, explanation)
+}
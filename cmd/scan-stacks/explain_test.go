@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestAnnotateStatus(t *testing.T) {
+	tests := map[string]string{
+		"UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS": "UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS " +
+			"(previous configuration restored and resources created by the failed update are being removed)",
+		"ROLLBACK_COMPLETE": "ROLLBACK_COMPLETE (creation failed and the created resources were removed; the stack can only be deleted)",
+		"DELETE_SKIPPED":    "DELETE_SKIPPED (kept on deletion because of its deletion policy)",
+		"SOMETHING_NEW":     "SOMETHING_NEW",
+	}
+
+	for status, want := range tests {
+		if got := annotateStatus(status); got != want {
+			t.Errorf("annotateStatus(%s) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestEveryStackStatusIsExplained(t *testing.T) {
+	for _, status := range cfTypes.StackStatus("").Values() {
+		if explainStatus(string(status)) == "" {
+			t.Errorf("stack status %s has no explanation", status)
+		}
+	}
+}
+
+func TestExplainAnnotatesTextOutput(t *testing.T) {
+	logs := captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusUpdateRollbackCompleteCleanupInProgress)
+	account := &fakeAccount{
+		regions:   []string{testDefaultRegion},
+		stacks:    map[string][]cfTypes.StackSummary{testDefaultRegion: {web}},
+		resources: map[string][]cfTypes.StackResourceSummary{*web.StackId: {testResource("Bucket", "AWS::S3::Bucket", "web-assets")}},
+	}
+
+	s := newTestScanner(t, account, true, "-explain")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	for _, want := range []string{
+		"  - Status: UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS (previous configuration restored and resources " +
+			"created by the failed update are being removed)\n",
+		"     - Status: CREATE_COMPLETE (created successfully)\n",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("text output does not contain %q:\n%s", want, logs)
+		}
+	}
+
+	if f := testFormatter(t, "-explain", "-output", "json"); f.Status("CREATE_COMPLETE") != "CREATE_COMPLETE" {
+		t.Error("-explain annotated a status of the JSON output")
+	}
+}
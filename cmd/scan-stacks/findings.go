@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// Finding types reported for notable stacks.
+const (
+	FindingTypeFailed  = "FAILED"
+	FindingTypeDrifted = "DRIFTED"
+)
+
+// stackFinding describes a stack that needs attention, either because it is in a failed state or has drifted.
+type stackFinding struct {
+	FindingType       string `json:"findingType"`
+	Account           string `json:"account"`
+	Region            string `json:"region"`
+	StackName         string `json:"stackName"`
+	StackID           string `json:"stackId"`
+	StackStatus       string `json:"stackStatus"`
+	StackStatusReason string `json:"stackStatusReason,omitempty"`
+	DriftStatus       string `json:"driftStatus,omitempty"`
+}
+
+// isFailedStackStatus reports whether status is one of the terminal *_FAILED stack statuses.
+func isFailedStackStatus(status cfTypes.StackStatus) bool {
+	return strings.HasSuffix(string(status), "_FAILED")
+}
+
+// findStackFindings returns a finding for every failed or drifted stack in region.
+func findStackFindings(account string, region string, stacks []cfTypes.StackSummary) []stackFinding {
+	var findings []stackFinding
+
+	for _, stack := range stacks {
+		driftStatus := ""
+		if stack.DriftInformation != nil {
+			driftStatus = string(stack.DriftInformation.StackDriftStatus)
+		}
+
+		finding := stackFinding{
+			Account:     account,
+			Region:      region,
+			StackName:   aws.ToString(stack.StackName),
+			StackID:     aws.ToString(stack.StackId),
+			StackStatus: string(stack.StackStatus),
+			DriftStatus: driftStatus,
+		}
+
+		if stack.StackStatusReason != nil {
+			finding.StackStatusReason = *stack.StackStatusReason
+		}
+
+		if isFailedStackStatus(stack.StackStatus) {
+			finding.FindingType = FindingTypeFailed
+			findings = append(findings, finding)
+		}
+
+		if driftStatus == string(cfTypes.StackDriftStatusDrifted) {
+			finding.FindingType = FindingTypeDrifted
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// Output formats selected with -output.
+const (
+	OutputText      = "text"
+	OutputJSON      = "json"
+	OutputCSV       = "csv"
+	OutputOCSF      = "ocsf"
+	OutputCanonical = "canonical"
+)
+
+// Default nil placeholders per output format. JSON always renders missing values as null.
+const (
+	TextNilPlaceholder = "<nil>"
+	CSVNilPlaceholder  = ""
+	JSONNilPlaceholder = "null"
+)
+
+// defaultNilPlaceholder returns the placeholder printed for missing values in the given output format.
+func defaultNilPlaceholder(output string) string {
+	switch output {
+	case OutputJSON, OutputOCSF:
+		return JSONNilPlaceholder
+	case OutputCSV:
+		return CSVNilPlaceholder
+	case OutputCanonical:
+		return CanonicalNilPlaceholder
+	default:
+		return TextNilPlaceholder
+	}
+}
+
+// formatter renders optional values for one output format.
+type formatter struct {
+	output         string
+	nilPlaceholder string
+	// explain appends a plain words explanation to the statuses logged in text output.
+	explain bool
+}
+
+// newFormatter returns a formatter for output, using nilPlaceholder for missing values.
+func newFormatter(output string, nilPlaceholder string) formatter {
+	return formatter{output: output, nilPlaceholder: nilPlaceholder}
+}
+
+// String returns s, or the nil placeholder when s is nil.
+func (f formatter) String(s *string) string {
+	return nilSafeString(s, f.nilPlaceholder)
+}
+
+// Time returns tmp formatted as RFC3339, or the nil placeholder when tmp is nil.
+func (f formatter) Time(tmp *time.Time) string {
+	return nilSafeTime(tmp, "", f.nilPlaceholder)
+}
+
+// Status returns status, annotated with its explanation when explain is set.
+func (f formatter) Status(status string) string {
+	if f.explain {
+		return annotateStatus(status)
+	}
+
+	return status
+}
+
+// logStack logs the details of a stack of region in text output.
+func (f formatter) logStack(region string, stack stackReport) {
+	log.Printf("- Stack: %s/%s", region, f.String(stack.StackName))
+	log.Printf("  - Id: %s", f.String(stack.StackID))
+	log.Printf("  - Name: %s", f.String(stack.StackName))
+	log.Printf("  - Status: %s", f.Status(stack.StackStatus))
+	log.Printf("  - Status Reason: %s", f.String(stack.StackStatusReason))
+	log.Printf("  - Parent Id: %s", f.String(stack.ParentID))
+	log.Printf("  - Root Id: %s", f.String(stack.RootID))
+	log.Printf("  - StackSet: %s", f.String(stack.StackSetName))
+	log.Printf("  - Creation Time: %s", f.Time(stack.CreationTime))
+	log.Printf("  - Last Updated Time: %s", f.Time(stack.LastUpdatedTime))
+	log.Printf("  - Deletion Time: %s", f.Time(stack.DeletionTime))
+}
+
+// logResource logs the details of a resource of the stack named stackName in region in text output.
+func (f formatter) logResource(region string, stackName *string, resource resourceReport) {
+	log.Printf("  - Stack Resource: %s/%s/%s", region, f.String(stackName), f.String(resource.LogicalResourceID))
+	log.Printf("     - Physical Resource Id: %s", f.String(resource.PhysicalResourceID))
+	log.Printf("     - Logical Resource Id: %s", f.String(resource.LogicalResourceID))
+	log.Printf("     - Resource Type: %s", f.String(resource.ResourceType))
+	log.Printf("     - Status: %s", f.Status(resource.ResourceStatus))
+	log.Printf("     - Status Reason: %s", f.String(resource.ResourceStatusReason))
+	log.Printf("     - Last Updated Time: %s", f.Time(resource.LastUpdatedTimestamp))
+	f.logResourceTimeline(resource.Timeline)
+}
+
+// writeReport renders report to w in the structured output format of f.
+func (f formatter) writeReport(w io.Writer, report *scanReport) error {
+	switch f.output {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write JSON report: %w", err)
+		}
+
+		return nil
+	case OutputCSV:
+		return f.writeCSVReport(w, report)
+	case OutputCanonical:
+		return f.writeCanonicalReport(w, report)
+	default:
+		return fmt.Errorf("output format '%s' has no report writer", f.output)
+	}
+}
+
+// csvHeader is the header row of the CSV report; there is one row per stack resource.
+var csvHeader = []string{
+	"account", "region", "stack_id", "stack_name", "stack_status", "stack_status_reason",
+	"stack_set_name", "logical_resource_id", "physical_resource_id", "resource_type", "resource_status",
+	"resource_status_reason", "resource_last_updated",
+}
+
+// writeCSVReport writes one row per stack resource, and a row with empty resource columns for stacks without resources.
+// The regions left out for having no stacks follow as rows with only the account and region, so the CSV keeps the
+// scan coverage.
+func (f formatter) writeCSVReport(w io.Writer, report *scanReport) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV report: %w", err)
+	}
+
+	for _, region := range report.Regions {
+		for _, stack := range region.Stacks {
+			stackColumns := []string{
+				region.Account, region.Region, f.String(stack.StackID), f.String(stack.StackName),
+				stack.StackStatus, f.String(stack.StackStatusReason), f.String(stack.StackSetName),
+			}
+
+			if len(stack.Resources) == 0 {
+				if err := writer.Write(append(stackColumns, "", "", "", "", "", "")); err != nil {
+					return fmt.Errorf("failed to write CSV report: %w", err)
+				}
+			}
+
+			for _, resource := range stack.Resources {
+				row := append(append([]string{}, stackColumns...),
+					f.String(resource.LogicalResourceID), f.String(resource.PhysicalResourceID),
+					f.String(resource.ResourceType), resource.ResourceStatus,
+					f.String(resource.ResourceStatusReason), f.Time(resource.LastUpdatedTimestamp))
+
+				if err := writer.Write(row); err != nil {
+					return fmt.Errorf("failed to write CSV report: %w", err)
+				}
+			}
+		}
+	}
+
+	for _, key := range report.RegionsWithoutStacks {
+		account, region, _ := parseRegionCoverageKey(key)
+
+		row := make([]string, len(csvHeader))
+		row[0], row[1] = account, region
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV report: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV report: %w", err)
+	}
+
+	return nil
+}
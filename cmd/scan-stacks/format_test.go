@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// testReport returns a report of one stack, without a status reason, with one resource.
+func testReport() *scanReport {
+	stack := newStackReport(testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete))
+	stack.Resources = append(stack.Resources, newResourceReport(testResource("Bucket", "AWS::S3::Bucket", "app-bucket")))
+
+	return &scanReport{
+		GeneratedAt: testTime,
+		Regions:     []regionReport{{Account: testAccount, Region: testDefaultRegion, Stacks: []stackReport{stack}}},
+	}
+}
+
+// testTime is the fixed scan time of the test reports.
+var testTime = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// testFormatter returns the formatter selected by the command line args.
+func testFormatter(t *testing.T, args ...string) formatter {
+	t.Helper()
+
+	opts, err := parseOptions("scan-stacks", args)
+	if err != nil {
+		t.Fatalf("parseOptions(%v) error: %v", args, err)
+	}
+
+	return opts.formatter()
+}
+
+// csvColumn returns the value of column in the first data row of a CSV report.
+func csvColumn(t *testing.T, report string, column string) string {
+	t.Helper()
+
+	rows, err := csv.NewReader(strings.NewReader(report)).ReadAll()
+	if err != nil || len(rows) < 2 {
+		t.Fatalf("reading CSV report %q: %v", report, err)
+	}
+
+	for i, name := range rows[0] {
+		if name == column {
+			return rows[1][i]
+		}
+	}
+
+	t.Fatalf("CSV report has no %s column", column)
+
+	return ""
+}
+
+func TestNilPlaceholderPerFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"text", nil, "  - Status Reason: <nil>\n"},
+		{"text override", []string{"-nil-placeholder", "n/a"}, "  - Status Reason: n/a\n"},
+		{"csv", []string{"-output", "csv"}, ""},
+		{"csv override", []string{"-output", "csv", "-nil-placeholder", "NULL"}, "NULL"},
+		{"json", []string{"-output", "json"}, `"stackStatusReason": null`},
+		{"json ignores override", []string{"-output", "json", "-nil-placeholder", "n/a"}, `"stackStatusReason": null`},
+		{"canonical", []string{"-output", "canonical"}, "    status_reason: -\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testFormatter(t, tt.args...)
+			report := testReport()
+
+			if f.output == OutputText {
+				logs := captureLog(t)
+				f.logStack(testDefaultRegion, report.Regions[0].Stacks[0])
+
+				if !strings.Contains(logs.String(), tt.want) {
+					t.Errorf("text output does not contain %q:\n%s", tt.want, logs)
+				}
+
+				return
+			}
+
+			var buf bytes.Buffer
+			if err := f.writeReport(&buf, report); err != nil {
+				t.Fatalf("writeReport error: %v", err)
+			}
+
+			got := buf.String()
+
+			if f.output == OutputCSV {
+				if value := csvColumn(t, got, "stack_status_reason"); value != tt.want {
+					t.Errorf("stack_status_reason = %q, want %q", value, tt.want)
+				}
+
+				return
+			}
+
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("%s output does not contain %q:\n%s", f.output, tt.want, got)
+			}
+		})
+	}
+}
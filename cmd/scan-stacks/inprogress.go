@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// ExitCodeInProgress is the exit code of -in-progress-count when any stack operation is in progress.
+const ExitCodeInProgress = 3
+
+// inProgressStack is a stack with an operation in progress.
+type inProgressStack struct {
+	Account     string
+	Region      string
+	StackName   string
+	StackStatus string
+}
+
+// findInProgressStacks returns the stacks in region that have an operation in progress.
+func findInProgressStacks(account string, region string, stacks []cfTypes.StackSummary) []inProgressStack {
+	var inProgress []inProgressStack
+
+	for _, stack := range stacks {
+		if isStableStackStatus(stack.StackStatus) {
+			continue
+		}
+
+		inProgress = append(inProgress, inProgressStack{
+			Account:     account,
+			Region:      region,
+			StackName:   aws.ToString(stack.StackName),
+			StackStatus: string(stack.StackStatus),
+		})
+	}
+
+	return inProgress
+}
+
+// writeInProgressCount writes the number of in-progress stacks and lists them, and returns the exit code:
+// 0 when nothing is in progress, ExitCodeInProgress otherwise.
+func writeInProgressCount(w io.Writer, inProgress []inProgressStack) (int, error) {
+	if _, err := fmt.Fprintf(w, "%d\n", len(inProgress)); err != nil {
+		return 0, fmt.Errorf("failed to write in-progress count: %w", err)
+	}
+
+	for _, stack := range inProgress {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", stack.Account, stack.Region, stack.StackName, stack.StackStatus); err != nil {
+			return 0, fmt.Errorf("failed to write in-progress stacks: %w", err)
+		}
+	}
+
+	if len(inProgress) > 0 {
+		return ExitCodeInProgress, nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestInProgressCountAndExitCode(t *testing.T) {
+	captureLog(t)
+
+	tests := []struct {
+		name   string
+		stacks map[string][]cfTypes.StackSummary
+		want   string
+		code   int
+	}{
+		{
+			name: "operations in progress",
+			stacks: map[string][]cfTypes.StackSummary{
+				testDefaultRegion: {
+					testStack(testDefaultRegion, "web", cfTypes.StackStatusUpdateInProgress),
+					testStack(testDefaultRegion, "queue", cfTypes.StackStatusCreateComplete),
+					testStack(testDefaultRegion, "old", cfTypes.StackStatusRollbackComplete),
+				},
+				"us-east-1": {
+					testStack("us-east-1", "cdn", cfTypes.StackStatusUpdateRollbackCompleteCleanupInProgress),
+					testStack("us-east-1", "review", cfTypes.StackStatusReviewInProgress),
+				},
+			},
+			want: "3\n" +
+				testAccount + "\t" + testDefaultRegion + "\tweb\tUPDATE_IN_PROGRESS\n" +
+				testAccount + "\tus-east-1\tcdn\tUPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS\n" +
+				testAccount + "\tus-east-1\treview\tREVIEW_IN_PROGRESS\n",
+			code: ExitCodeInProgress,
+		},
+		{
+			name: "nothing in progress",
+			stacks: map[string][]cfTypes.StackSummary{
+				testDefaultRegion: {testStack(testDefaultRegion, "web", cfTypes.StackStatusUpdateComplete)},
+			},
+			want: "0\n",
+			code: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &fakeAccount{regions: []string{testDefaultRegion, "us-east-1"}, stacks: tt.stacks}
+			s := newTestScanner(t, account, false, "-in-progress-count", "-concurrency", "1")
+
+			if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+				t.Fatalf("scanAccount error: %v", err)
+			}
+
+			var out bytes.Buffer
+
+			code, err := writeInProgressCount(&out, s.inProgress)
+			if err != nil {
+				t.Fatalf("writeInProgressCount error: %v", err)
+			}
+
+			if out.String() != tt.want || code != tt.code {
+				t.Errorf("output %q with exit code %d, want %q with %d", out.String(), code, tt.want, tt.code)
+			}
+
+			// Only the regions and their stacks are listed, never the stack resources.
+			if account.calls != 1+len(account.regions) {
+				t.Errorf("API calls = %d, want %d", account.calls, 1+len(account.regions))
+			}
+		})
+	}
+}
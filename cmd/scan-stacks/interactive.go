@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/ktr0731/go-fuzzyfinder"
+	"golang.org/x/term"
+)
+
+// pickerItem is a stack or resource the interactive picker can select.
+type pickerItem struct {
+	Label  string
+	Detail any
+}
+
+// pickerResource is the detail shown for a selected resource, with its owning stack.
+type pickerResource struct {
+	Account   string         `json:"account"`
+	Region    string         `json:"region"`
+	StackName *string        `json:"stackName"`
+	Resource  resourceReport `json:"resource"`
+}
+
+// isTerminal reports whether file is an interactive terminal.
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// pickerItems lists every stack and resource of report as selectable items.
+func pickerItems(report *scanReport) []pickerItem {
+	var items []pickerItem
+
+	for _, region := range report.Regions {
+		for _, stack := range region.Stacks {
+			summary := stack
+			summary.Resources = nil
+
+			items = append(items, pickerItem{
+				Label:  fmt.Sprintf("stack    %s/%s %s", region.Region, aws.ToString(stack.StackName), stack.StackStatus),
+				Detail: summary,
+			})
+
+			for _, resource := range stack.Resources {
+				items = append(items, pickerItem{
+					Label: fmt.Sprintf("resource %s/%s/%s %s %s", region.Region, aws.ToString(stack.StackName),
+						aws.ToString(resource.LogicalResourceID), aws.ToString(resource.ResourceType),
+						aws.ToString(resource.PhysicalResourceID)),
+					Detail: pickerResource{Account: region.Account, Region: region.Region, StackName: stack.StackName, Resource: resource},
+				})
+			}
+		}
+	}
+
+	return items
+}
+
+// pickerDetail renders the details of item as indented JSON.
+func pickerDetail(item pickerItem) (string, error) {
+	detail, err := json.MarshalIndent(item.Detail, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render selection: %w", err)
+	}
+
+	return string(detail), nil
+}
+
+// runPicker opens a fuzzy finder over items in the terminal, previewing the details of the highlighted item,
+// and writes the details of the selected one to out. Quitting the finder (Esc or Ctrl-C) selects nothing.
+func runPicker(out io.Writer, items []pickerItem) error {
+	index, err := fuzzyfinder.Find(items, func(i int) string {
+		return items[i].Label
+	}, fuzzyfinder.WithPromptString("search> "), fuzzyfinder.WithPreviewWindow(func(i int, _ int, _ int) string {
+		if i < 0 {
+			return ""
+		}
+
+		detail, _ := pickerDetail(items[i])
+
+		return detail
+	}))
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run picker: %w", err)
+	}
+
+	detail, err := pickerDetail(items[index])
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(out, detail); err != nil {
+		return fmt.Errorf("failed to write selection: %w", err)
+	}
+
+	return nil
+}
+
+// writePickerItems writes the label of every item, one per line: the non-interactive output of -interactive.
+func writePickerItems(out io.Writer, items []pickerItem) error {
+	for _, item := range items {
+		if _, err := fmt.Fprintln(out, item.Label); err != nil {
+			return fmt.Errorf("failed to write picker items: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// runInteractive opens the picker over items when stdin and stdout are terminals. Otherwise it falls back to
+// writing the items to stdout, so -interactive output can still be piped or redirected.
+func runInteractive(stdin *os.File, stdout *os.File, items []pickerItem) error {
+	if !isTerminal(stdin) || !isTerminal(stdout) {
+		log.Println("Not running in a terminal, -interactive lists the stacks and resources instead")
+
+		return writePickerItems(stdout, items)
+	}
+
+	return runPicker(stdout, items)
+}
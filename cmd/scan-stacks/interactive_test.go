@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInteractiveFallsBackWithoutTerminal(t *testing.T) {
+	logs := captureLog(t)
+
+	stdin, err := os.Open(filepath.Join("testdata", "terraform.tfstate"))
+	if err != nil {
+		t.Fatalf("opening stdin: %v", err)
+	}
+	defer stdin.Close()
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatalf("creating stdout: %v", err)
+	}
+	defer stdout.Close()
+
+	if err := runInteractive(stdin, stdout, pickerItems(testReport())); err != nil {
+		t.Fatalf("runInteractive error: %v", err)
+	}
+
+	output, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("reading stdout: %v", err)
+	}
+
+	want := "stack    us-west-2/app CREATE_COMPLETE\n" +
+		"resource us-west-2/app/Bucket AWS::S3::Bucket app-bucket\n"
+	if string(output) != want {
+		t.Errorf("output =\n%s\nwant\n%s", output, want)
+	}
+
+	if !strings.Contains(logs.String(), "Not running in a terminal") {
+		t.Errorf("log does not explain the fallback:\n%s", logs)
+	}
+}
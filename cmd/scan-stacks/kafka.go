@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// KafkaBatchSize is the number of messages sent to Kafka in one request.
+	KafkaBatchSize = 100
+	// KafkaBatchTimeout is how long the writer waits for a partition's batch to fill. The Hash balancer
+	// spreads each batch of KafkaBatchSize messages over the partitions, where the batches never fill, so
+	// the writer's default of one second would stall every WriteMessages call.
+	KafkaBatchTimeout = 5 * time.Millisecond
+)
+
+// kafkaProducer is the part of the Kafka writer used by kafkaPublisher.
+type kafkaProducer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// parseKafkaTarget splits a -kafka value of the form broker[,broker...],topic.
+func parseKafkaTarget(value string) ([]string, string, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 1 {
+		return nil, "", fmt.Errorf("-kafka must have the form broker[,broker...],topic: %s", value)
+	}
+
+	brokers, topic := parts[:len(parts)-1], parts[len(parts)-1]
+
+	for _, broker := range brokers {
+		if broker == "" {
+			return nil, "", fmt.Errorf("-kafka has an empty broker: %s", value)
+		}
+	}
+
+	if topic == "" {
+		return nil, "", fmt.Errorf("-kafka has an empty topic: %s", value)
+	}
+
+	return brokers, topic, nil
+}
+
+// newKafkaWriter returns a Kafka writer for topic that waits for all in-sync replicas and keeps the
+// messages of a stack on one partition.
+func newKafkaWriter(brokers []string, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchSize:    KafkaBatchSize,
+		BatchTimeout: KafkaBatchTimeout,
+	}
+}
+
+// kafkaPublisher publishes the stack and resource scan events to Kafka in batches. It is used by the
+// single consumer of the scan pipeline and is not safe for concurrent use.
+type kafkaPublisher struct {
+	ctx       context.Context
+	producer  kafkaProducer
+	batchSize int
+	batch     []kafka.Message
+	published int
+	failed    int
+}
+
+// newKafkaPublisher returns a publisher writing batches of batchSize messages to producer.
+func newKafkaPublisher(ctx context.Context, producer kafkaProducer, batchSize int) *kafkaPublisher {
+	return &kafkaPublisher{ctx: ctx, producer: producer, batchSize: batchSize}
+}
+
+// kafkaMessageKey returns the message key of event, the stack name, so the messages of a stack stay in order.
+func kafkaMessageKey(event scanEvent) string {
+	stackName := ""
+
+	switch {
+	case event.Stack != nil:
+		stackName = event.Stack.StackName
+	case event.Resource != nil:
+		stackName = event.Resource.StackName
+	}
+
+	return fmt.Sprintf("%s/%s/%s", event.Account, event.Region, stackName)
+}
+
+// Publish queues event as a message and sends the batch when it is full. Delivery errors are logged
+// and counted, so that a broker outage does not stop the scan; Flush reports them.
+func (p *kafkaPublisher) Publish(event scanEvent) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error encoding Kafka message: %v", err)
+		p.failed++
+
+		return
+	}
+
+	p.batch = append(p.batch, kafka.Message{Key: []byte(kafkaMessageKey(event)), Value: value})
+
+	if len(p.batch) >= p.batchSize {
+		p.send()
+	}
+}
+
+// send writes the queued messages to Kafka.
+func (p *kafkaPublisher) send() {
+	if len(p.batch) == 0 {
+		return
+	}
+
+	if err := p.producer.WriteMessages(p.ctx, p.batch...); err != nil {
+		log.Printf("Error sending %d message(s) to Kafka: %v", len(p.batch), err)
+		p.failed += len(p.batch)
+	} else {
+		p.published += len(p.batch)
+	}
+
+	p.batch = nil
+}
+
+// Flush sends the queued messages and closes the producer. It returns an error when any message was not delivered.
+func (p *kafkaPublisher) Flush() error {
+	p.send()
+
+	if err := p.producer.Close(); err != nil {
+		return fmt.Errorf("failed to close Kafka producer: %w", err)
+	}
+
+	if p.failed > 0 {
+		return fmt.Errorf("failed to deliver %d of %d message(s) to Kafka", p.failed, p.failed+p.published)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeKafkaProducer records the messages of every WriteMessages call, or fails them with err.
+type fakeKafkaProducer struct {
+	err     error
+	batches [][]kafka.Message
+	closed  bool
+}
+
+func (f *fakeKafkaProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.batches = append(f.batches, msgs)
+
+	return nil
+}
+
+func (f *fakeKafkaProducer) Close() error {
+	f.closed = true
+
+	return nil
+}
+
+func TestKafkaPublishesOneMessagePerStackAndResource(t *testing.T) {
+	captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	queue := testStack(testDefaultRegion, "queue", cfTypes.StackStatusUpdateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				testResource("Role", "AWS::IAM::Role", "web-role"),
+			},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "jobs")},
+		},
+	}
+
+	producer := &fakeKafkaProducer{}
+
+	s := newTestScanner(t, account, false, "-kafka", "broker-1:9092,scans", "-concurrency", "1")
+	s.kafka = newKafkaPublisher(context.Background(), producer, 2)
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	if err := s.kafka.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	if !producer.closed {
+		t.Error("Flush did not close the producer")
+	}
+
+	var messages []string
+
+	for _, batch := range producer.batches {
+		if len(batch) > 2 {
+			t.Errorf("batch of %d messages, want at most 2", len(batch))
+		}
+
+		for _, message := range batch {
+			var event scanEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				t.Fatalf("decoding message %q: %v", message.Value, err)
+			}
+
+			step := event.Type
+			if event.Resource != nil {
+				step += " " + event.Resource.LogicalResourceID
+			}
+
+			messages = append(messages, string(message.Key)+" "+step)
+		}
+	}
+
+	prefix := testAccount + "/" + testDefaultRegion + "/"
+	want := []string{
+		prefix + "web stack-found",
+		prefix + "queue stack-found",
+		prefix + "web resource-found Bucket",
+		prefix + "web resource-found Role",
+		prefix + "queue resource-found Queue",
+	}
+
+	if !slices.Equal(messages, want) {
+		t.Errorf("messages =\n%q\nwant\n%q", messages, want)
+	}
+}
+
+func TestKafkaFlushReportsUndeliveredMessages(t *testing.T) {
+	logs := captureLog(t)
+
+	producer := &fakeKafkaProducer{err: errors.New("broker unavailable")}
+	publisher := newKafkaPublisher(context.Background(), producer, 2)
+
+	for _, name := range []string{"Bucket", "Role", "Queue"} {
+		publisher.Publish(scanEvent{Type: EventResourceFound, Region: testDefaultRegion, Resource: &eventResource{StackName: "web", LogicalResourceID: name}})
+	}
+
+	err := publisher.Flush()
+	if err == nil || !strings.Contains(err.Error(), "failed to deliver 3 of 3 message(s)") {
+		t.Errorf("Flush error = %v, want 3 undelivered messages", err)
+	}
+
+	if want := "Error sending 2 message(s) to Kafka: broker unavailable"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
+
+func TestNewKafkaWriterSendsPartialPartitionBatches(t *testing.T) {
+	w := newKafkaWriter([]string{"a:9092"}, "scans")
+
+	// Each batch is hashed over the partitions, so a partition's share is sent after BatchTimeout.
+	if w.BatchSize != KafkaBatchSize || w.BatchTimeout != KafkaBatchTimeout || w.BatchTimeout >= 100*time.Millisecond {
+		t.Errorf("BatchSize = %d, BatchTimeout = %s, want %d and a few milliseconds", w.BatchSize, w.BatchTimeout,
+			KafkaBatchSize)
+	}
+}
+
+func TestParseKafkaTarget(t *testing.T) {
+	brokers, topic, err := parseKafkaTarget("a:9092,b:9092,scans")
+	if err != nil || !slices.Equal(brokers, []string{"a:9092", "b:9092"}) || topic != "scans" {
+		t.Errorf("parseKafkaTarget = %v, %q, %v", brokers, topic, err)
+	}
+
+	for _, value := range []string{"scans", ",scans", "a:9092,"} {
+		if _, _, err := parseKafkaTarget(value); err == nil {
+			t.Errorf("parseKafkaTarget(%q) returned no error", value)
+		}
+	}
+}
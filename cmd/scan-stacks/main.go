@@ -13,9 +13,22 @@ import (
 	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// awsConfigOptions returns the options used to load the AWS configuration shared by every client.
+func awsConfigOptions(opts *options, region string) []func(*config.LoadOptions) error {
+	loadOptions := []func(*config.LoadOptions) error{config.WithRegion(region)}
+
+	if opts.dualStack {
+		loadOptions = append(loadOptions, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+
+	return loadOptions
+}
+
 // getCallerIdentity retrieves the AWS account ID and user ID.
 func getCallerIdentity(ctx context.Context, cfg aws.Config) (*sts.GetCallerIdentityOutput, error) {
 	stsClient := sts.NewFromConfig(cfg)
@@ -41,14 +54,13 @@ func getAWSRegions(ctx context.Context, cfg aws.Config, allRegions bool) (*[]ec2
 
 	output, err := ec2Client.DescribeRegions(ctx, input)
 	if err != nil {
-		log.Fatalf("Unable to DescribeRegions: %v", err)
 		return nil, fmt.Errorf("failed to describe regions: %w", err)
 	}
 
 	return &output.Regions, nil
 }
 
-// cloudFormationListStacks retrieves a list of CloudFormation stacks.
+// cloudFormationListStacks retrieves a list of CloudFormation stacks in the region of cfg.
 func cloudFormationListStacks(ctx context.Context, cfg aws.Config) (*[]cfTypes.StackSummary, error) {
 	cfClient := cloudformation.NewFromConfig(cfg)
 
@@ -93,7 +105,6 @@ func cloudFormationListStacks(ctx context.Context, cfg aws.Config) (*[]cfTypes.S
 
 		output, err := cfClient.ListStacks(ctx, &input)
 		if err != nil {
-			log.Fatalf("Unable to ListStacks: %v", err)
 			return nil, fmt.Errorf("failed to list stacks: %w", err)
 		}
 
@@ -112,10 +123,24 @@ func cloudFormationListStacks(ctx context.Context, cfg aws.Config) (*[]cfTypes.S
 	return &allStacks, nil
 }
 
-// cloudFormationListStackResources retrieves a list of CloudFormation stack resources.
-func cloudFormationListStackResources(ctx context.Context, cfg aws.Config, stackID string) (*[]cfTypes.StackResourceSummary, error) {
-	cfClient := cloudformation.NewFromConfig(cfg)
+// stackResourcePageFunc receives one page of stack resources as soon as it has been fetched.
+type stackResourcePageFunc func(page []cfTypes.StackResourceSummary) error
+
+// cloudFormationListStackResources retrieves a list of CloudFormation stack resources,
+// pacing every request attempt, retries included, with throttle when it is not nil. When onPage is not nil, each page is handed to onPage as it arrives instead of being
+// accumulated, so very large stacks are never held in memory, and the returned list is empty.
+func cloudFormationListStackResources(ctx context.Context, cfg aws.Config, stackID string, throttle *adaptiveThrottle, onPage stackResourcePageFunc) (*[]cfTypes.StackResourceSummary, error) {
+	cfClient := cloudformation.NewFromConfig(cfg, func(o *cloudformation.Options) {
+		if throttle != nil {
+			o.Retryer = newThrottledRetryer(o.Retryer, throttle)
+		}
+	})
+
+	return listStackResourcePages(ctx, cfClient, stackID, onPage)
+}
 
+// listStackResourcePages lists the resources of a stack with cfClient, as described for cloudFormationListStackResources.
+func listStackResourcePages(ctx context.Context, cfClient cloudformation.ListStackResourcesAPIClient, stackID string, onPage stackResourcePageFunc) (*[]cfTypes.StackResourceSummary, error) {
 	var allStackResources []cfTypes.StackResourceSummary
 	var nextToken *string
 
@@ -127,12 +152,18 @@ func cloudFormationListStackResources(ctx context.Context, cfg aws.Config, stack
 
 		output, err := cfClient.ListStackResources(ctx, &input)
 		if err != nil {
-			log.Fatalf("Unable to ListStacks: %v", err)
-			return nil, fmt.Errorf("failed to list stacks: %w", err)
+			return nil, fmt.Errorf("failed to list stack resources: %w", err)
 		}
 
-		// Append the current page of stacks to the result
-		allStackResources = append(allStackResources, output.StackResourceSummaries...)
+		if onPage != nil {
+			// Stream the current page of stack resources to the caller
+			if perr := onPage(output.StackResourceSummaries); perr != nil {
+				return nil, fmt.Errorf("failed to process stack resources page: %w", perr)
+			}
+		} else {
+			// Append the current page of stacks to the result
+			allStackResources = append(allStackResources, output.StackResourceSummaries...)
+		}
 
 		// Check if there is another page
 		if output.NextToken == nil {
@@ -150,109 +181,386 @@ func main() {
 	verbose := true
 	ctx := context.Background()
 
+	opts, oerr := parseOptions(os.Args[0], os.Args[1:])
+	if oerr != nil {
+		log.Fatalf("Unable to parse options: %v", oerr)
+		return
+	}
+
+	if opts.listAPIs {
+		for _, operation := range apiOperations(opts) {
+			fmt.Println(operation)
+		}
+
+		return
+	}
+
+	if opts.genPolicy {
+		if perr := writePolicy(os.Stdout, opts); perr != nil {
+			log.Fatalf("Unable to generate policy: %v", perr)
+		}
+
+		return
+	}
+
+	if opts.aggregatePath != "" {
+		points, aerr := aggregateReports(opts.aggregatePath, opts.dedupKey)
+		if aerr != nil {
+			log.Fatalf("Unable to aggregate reports: %v", aerr)
+			return
+		}
+
+		if werr := writeAggregate(os.Stdout, opts.output, points); werr != nil {
+			log.Fatalf("Unable to write aggregate: %v", werr)
+		}
+
+		return
+	}
+
 	region := os.Getenv("AWS_REGION")
 	if region == "" {
 		region = "us-west-2"
 	}
 
+	var tfState *terraformState
+	if opts.tfStatePath != "" {
+		var terr error
+
+		tfState, terr = loadTerraformState(opts.tfStatePath)
+		if terr != nil {
+			log.Fatalf("Unable to load Terraform state: %v", terr)
+			return
+		}
+	}
+
+	var policy *resourcePolicy
+	if opts.policyPath != "" {
+		var perr error
+
+		policy, perr = loadResourcePolicy(opts.policyPath)
+		if perr != nil {
+			log.Fatalf("Unable to load policy: %v", perr)
+			return
+		}
+	}
+
+	var baseline *scanReport
+	if opts.baselinePath != "" {
+		var berr error
+
+		baseline, berr = readScanReport(opts.baselinePath)
+		if berr != nil {
+			log.Fatalf("Unable to load baseline: %v", berr)
+			return
+		}
+	}
+
+	var emitter *eventEmitter
+	if opts.emitEventsPath == "-" {
+		emitter = newEventEmitter(os.Stdout)
+	} else if opts.emitEventsPath != "" {
+		eventsFile, eerr := os.Create(opts.emitEventsPath)
+		if eerr != nil {
+			log.Fatalf("Unable to open events output: %v", eerr)
+			return
+		}
+		defer eventsFile.Close()
+
+		emitter = newEventEmitter(eventsFile)
+	}
+
 	// Load AWS configuration.
-	cfg, cerr := config.LoadDefaultConfig(ctx)
+	cfg, cerr := config.LoadDefaultConfig(ctx, awsConfigOptions(opts, region)...)
 	if cerr != nil {
 		log.Fatalf("Unable to load AWS configuration: %v", cerr)
 		return
 	}
 
-	identity, ierr := getCallerIdentity(ctx, cfg)
-	if ierr != nil {
-		log.Fatalf("Unable to load AWS Caller Identity: %v", ierr)
-		return
-	}
+	stackCfg := cfg.Copy()
+	stackCfg.Region = region
+
+	if opts.waitForStable {
+		describe := func(ctx context.Context, stackName string) (*cfTypes.Stack, error) {
+			return cloudFormationDescribeStack(ctx, stackCfg, stackName)
+		}
+
+		status, werr := waitForStableStack(ctx, pollStackStatus(describe, opts.stackName), opts.waitInterval,
+			opts.waitTimeout)
+		if werr != nil {
+			log.Fatalf("Unable to wait for stack '%s': %v", opts.stackName, werr)
+			return
+		}
 
-	if verbose {
-		log.Printf("AWS Account ID: %s\n", *identity.Account)
-		log.Printf("AWS User ID: %s\n", *identity.UserId)
-		log.Printf("AWS ARN: %s\n", *identity.Arn)
-		log.Println()
+		os.Exit(stableExitCode(status))
 	}
 
-	regions, rerr := getAWSRegions(ctx, cfg, false)
-	if rerr != nil {
-		log.Fatalf("Unable to load AWS Regions: %v", rerr)
+	if opts.outputsAsEnv {
+		stack, derr := cloudFormationDescribeStack(ctx, stackCfg, opts.stackName)
+		if derr != nil {
+			log.Fatalf("Unable to describe stack '%s': %v", opts.stackName, derr)
+			return
+		}
+
+		if werr := writeOutputsAsEnv(os.Stdout, stack.Outputs); werr != nil {
+			log.Fatalf("Unable to print stack outputs: %v", werr)
+		}
+
 		return
 	}
 
-	allRegionNames := []string{region} // Add more regions if needed
+	// A cached report needs no caller identity, so a cache hit only calls STS when -check-skew asks for it, or
+	// when the cache has yet to learn the account of the credentials.
+	var identity *sts.GetCallerIdentityOutput
+
+	var cache *reportCache
+
+	if opts.cacheDir != "" {
+		if isReportCacheable(opts) {
+			var cerr error
+
+			cache, cerr = openReportCache(ctx, cfg, opts, region, func(ctx context.Context) (string, error) {
+				var ierr error
+
+				identity, ierr = getCallerIdentity(ctx, cfg)
+				if ierr != nil {
+					return "", ierr
+				}
 
-	for _, region := range *regions {
-		if region.RegionName != nil {
-			if verbose {
-				log.Printf("Adding region '%s'\n", *region.RegionName)
+				return aws.ToString(identity.Account), nil
+			})
+			if cerr != nil {
+				log.Fatalf("Unable to open report cache: %v", cerr)
+				return
 			}
+		} else {
+			log.Println("The enabled reports need a live scan, -cache-dir ignored")
+		}
+	}
+
+	cached, cacheHit := cache.Load(time.Now())
+
+	if identity == nil && (!cacheHit || opts.checkSkew > 0) {
+		var ierr error
 
-			allRegionNames = append(allRegionNames, *region.RegionName)
+		identity, ierr = getCallerIdentity(ctx, cfg)
+		if ierr != nil {
+			log.Fatalf("Unable to load AWS Caller Identity: %v", ierr)
+			return
 		}
 	}
 
-	log.Printf("All detected AWS Regions: %v\n", allRegionNames)
+	if identity != nil {
+		if opts.checkSkew > 0 {
+			checkClockSkew(identity.ResultMetadata, time.Now(), opts.checkSkew)
+		}
+
+		if verbose {
+			log.Printf("AWS Account ID: %s\n", *identity.Account)
+			log.Printf("AWS User ID: %s\n", *identity.UserId)
+			log.Printf("AWS ARN: %s\n", *identity.Arn)
+			log.Println()
+		}
+	}
 
-	log.Println("Checking each region for stacks...")
+	scan := newScanner(opts, verbose, emitter, tfState)
+	scan.policy = policy
 
-	for _, regionName := range allRegionNames {
-		log.Printf("- Region: %s\n", regionName)
+	if opts.resourceTemplate != "" {
+		var terr error
 
-		stacks, serr := cloudFormationListStacks(ctx, cfg)
-		if serr != nil {
-			log.Printf("Error calling cloudFormationListStacks: %v", serr)
-			continue
+		scan.template, terr = newResourceTemplate(opts.resourceTemplate, scan.format, os.Stdout)
+		if terr != nil {
+			log.Fatalf("Unable to load resource template: %v", terr)
+			return
 		}
+	}
 
-		for _, stack := range *stacks {
-			if verbose {
-				log.Println("- Stack:")
-				log.Printf("  - Id: %s", NilSafeString(stack.StackId))
-				log.Printf("  - Name: %s", NilSafeString(stack.StackName))
-				log.Printf("  - Status: %s", stack.StackStatus)
-				log.Printf("  - Status Reason: %s", NilSafeString(stack.StackStatusReason))
-				log.Printf("  - Parent Id: %s", NilSafeString(stack.ParentId))
-				log.Printf("  - Root Id: %s", NilSafeString(stack.RootId))
-				log.Printf("  - Creation Time: %s", NilSafeTime(stack.CreationTime, ""))
-				log.Printf("  - Last Updated Time: %s", NilSafeTime(stack.LastUpdatedTime, ""))
-				log.Printf("  - Deletion Time: %s", NilSafeTime(stack.DeletionTime, ""))
-			}
+	if opts.kafkaTopic != "" {
+		scan.kafka = newKafkaPublisher(ctx, newKafkaWriter(opts.kafkaBrokers, opts.kafkaTopic), KafkaBatchSize)
+	}
 
-			stackResources, srerr := cloudFormationListStackResources(ctx, cfg, *stack.StackId)
-			if srerr != nil {
-				log.Printf("Error calling cloudFormationListStackResources: %v", srerr)
-				continue
-			}
+	if cacheHit {
+		log.Printf("Using the cached report generated at %s\n", cached.GeneratedAt.Format(time.RFC3339))
 
-			for _, stackResource := range *stackResources {
-				if verbose {
-					log.Println("  - Stack Resource:")
-					log.Printf("     - Physical Resource Id: %s", NilSafeString(stackResource.PhysicalResourceId))
-					log.Printf("     - Logical Resource Id: %s", NilSafeString(stackResource.LogicalResourceId))
-					log.Printf("     - Resource Type: %s", NilSafeString(stackResource.ResourceType))
-					log.Printf("     - Status: %s", stackResource.ResourceStatus)
-					log.Printf("     - Status Reason: %s", NilSafeString(stackResource.ResourceStatusReason))
-					log.Printf("     - Last Updated Time: %s", NilSafeTime(stackResource.LastUpdatedTimestamp, ""))
-				}
+		scan.useCachedReport(cached)
+	} else {
+		targets := []scanTarget{{AccountID: *identity.Account, Config: cfg}}
+
+		if opts.org {
+			var terr error
+
+			targets, terr = orgScanTargets(ctx, cfg, organizations.NewFromConfig(cfg), *identity.Arn, opts.orgRole)
+			if terr != nil {
+				log.Fatalf("Unable to enumerate AWS Organization accounts: %v", terr)
+				return
 			}
+
+			log.Printf("Scanning %d active AWS Organization account(s)\n", len(targets))
+		}
+
+		scan.scanAccounts(ctx, targets, region)
+
+		if werr := cache.Store(scan.report); werr != nil {
+			log.Printf("Error caching report: %v", werr)
+		}
+	}
+
+	if scan.kafka != nil {
+		if ferr := scan.kafka.Flush(); ferr != nil {
+			log.Fatalf("Unable to publish to Kafka: %v", ferr)
+			return
+		}
+	}
+
+	if opts.inProgressCount {
+		code, werr := writeInProgressCount(os.Stdout, scan.inProgress)
+		if werr != nil {
+			log.Fatalf("Unable to print in-progress count: %v", werr)
+			return
+		}
+
+		os.Exit(code)
+	}
+
+	if scan.report != nil {
+		scan.report = mergeReports(opts.dedupKey, scan.report)
+	}
+
+	if opts.output == OutputOCSF {
+		if werr := writeOCSFFindings(os.Stdout, scan.findings, scan.scanTime); werr != nil {
+			log.Fatalf("Unable to write OCSF findings: %v", werr)
+			return
+		}
+	} else if !scan.textOutput {
+		output := scan.report
+		if opts.onlyRegionsWithStacks {
+			output = omitEmptyRegions(output)
+		}
+
+		if werr := scan.format.writeReport(os.Stdout, output); werr != nil {
+			log.Fatalf("Unable to write report: %v", werr)
+			return
+		}
+	}
+
+	if opts.onlyRegionsWithStacks && scan.textOutput {
+		printRegionsWithoutStacks(scan.emptyRegions)
+	}
+
+	if opts.manifestPath == "-" {
+		if werr := writeManifest(os.Stdout, scan.report); werr != nil {
+			log.Fatalf("Unable to write manifest: %v", werr)
+			return
+		}
+	} else if opts.manifestPath != "" {
+		manifestFile, merr := os.Create(opts.manifestPath)
+		if merr != nil {
+			log.Fatalf("Unable to open manifest output: %v", merr)
+			return
+		}
+		defer manifestFile.Close()
+
+		if werr := writeManifest(manifestFile, scan.report); werr != nil {
+			log.Fatalf("Unable to write manifest: %v", werr)
+			return
+		}
+	}
+
+	if opts.statusCounts && scan.textOutput {
+		if werr := writeStatusCountsTable(os.Stdout, scan.statusCounts); werr != nil {
+			log.Fatalf("Unable to print status counts: %v", werr)
+			return
+		}
+	}
+
+	if opts.driftStale > 0 {
+		printStaleDriftReport(scan.staleDrift, opts.driftStale)
+	}
+
+	if opts.missingDeps {
+		printMissingDependencyReport(scan.missingDeps)
+	}
+
+	if opts.noPhysicalID {
+		printNoPhysicalIDReport(scan.noPhysicalID)
+	}
+
+	if opts.groupByTag != "" {
+		printTagGroupReport(scan.tagGroups, opts.groupByTag)
+	}
+
+	if policy != nil {
+		printPolicyViolationReport(scan.violations, opts.policyPath)
+	}
+
+	if scan.affected != nil {
+		printAffectedStacksReport(scan.affected)
+	}
+
+	if scan.shared != nil {
+		printSharedResourceReport(scan.shared)
+	}
+
+	if opts.rootCause {
+		printRootCauseReport(scan.rootCauses)
+	}
+
+	if opts.serviceMap {
+		if werr := writeServiceMap(os.Stdout, buildServiceMap(scan.resourceTypes)); werr != nil {
+			log.Fatalf("Unable to print service map: %v", werr)
+			return
+		}
+	}
+
+	if tfState != nil {
+		printTerraformCrossReport(crossReferenceTerraformState(tfState, scan.scannedResources))
+	}
+
+	var deviations []baselineDeviation
+	if baseline != nil {
+		deviations = compareToBaseline(mergeReports(opts.dedupKey, baseline), scan.report)
+		printBaselineReport(deviations, opts.baselinePath)
+	}
+
+	if opts.eventBusName != "" {
+		log.Printf("Sending %d finding(s) to EventBridge bus '%s'\n", len(scan.findings), opts.eventBusName)
+
+		perr := putFindingEvents(ctx, eventbridge.NewFromConfig(cfg), opts.eventBusName, scan.findings)
+		if perr != nil {
+			log.Fatalf("Unable to send findings to EventBridge: %v", perr)
+			return
 		}
+	}
+
+	if opts.interactive {
+		if perr := runInteractive(os.Stdin, os.Stdout, pickerItems(scan.report)); perr != nil {
+			log.Printf("Error in interactive picker: %v", perr)
+		}
+	}
 
-		log.Println("")
+	if opts.failOnDeviation && len(deviations) > 0 {
+		os.Exit(ExitCodeDeviation)
 	}
 }
 
 func NilSafeString(s *string) string {
+	return nilSafeString(s, TextNilPlaceholder)
+}
+
+func nilSafeString(s *string, placeholder string) string {
 	if s == nil {
-		return "<nil>"
+		return placeholder
 	}
 	return *s
 }
 
 func NilSafeTime(tmp *time.Time, fmt string) string {
+	return nilSafeTime(tmp, fmt, TextNilPlaceholder)
+}
+
+func nilSafeTime(tmp *time.Time, fmt string, placeholder string) string {
 	if tmp == nil {
-		return "<nil>"
+		return placeholder
 	}
 
 	if fmt == "" {
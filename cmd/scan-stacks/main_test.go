@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// fakeStackResourcesClient returns the resources of a stack in pages of pageSize, failing with err when it is set.
+type fakeStackResourcesClient struct {
+	resources []cfTypes.StackResourceSummary
+	pageSize  int
+	calls     int
+	err       error
+}
+
+func (f *fakeStackResourcesClient) ListStackResources(_ context.Context, params *cloudformation.ListStackResourcesInput, _ ...func(*cloudformation.Options)) (*cloudformation.ListStackResourcesOutput, error) {
+	f.calls++
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	start := 0
+	if params.NextToken != nil {
+		if _, err := fmt.Sscan(*params.NextToken, &start); err != nil {
+			return nil, err
+		}
+	}
+
+	end := min(start+f.pageSize, len(f.resources))
+	output := &cloudformation.ListStackResourcesOutput{StackResourceSummaries: f.resources[start:end]}
+
+	if end < len(f.resources) {
+		output.NextToken = aws.String(fmt.Sprint(end))
+	}
+
+	return output, nil
+}
+
+// testResources returns count stack resources with distinct logical ids.
+func testResources(count int) []cfTypes.StackResourceSummary {
+	resources := make([]cfTypes.StackResourceSummary, count)
+	for i := range resources {
+		resources[i] = testResource(fmt.Sprintf("Resource%d", i), "AWS::SQS::Queue", fmt.Sprintf("queue-%d", i))
+	}
+
+	return resources
+}
+
+func TestListStackResourcePagesStreamsEachPage(t *testing.T) {
+	client := &fakeStackResourcesClient{resources: testResources(250), pageSize: 100}
+
+	var pages []int
+
+	total := 0
+
+	listed, err := listStackResourcePages(context.Background(), client, "stack", func(page []cfTypes.StackResourceSummary) error {
+		pages = append(pages, len(page))
+		total += len(page)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("listStackResourcePages error: %v", err)
+	}
+
+	if fmt.Sprint(pages) != "[100 100 50]" {
+		t.Errorf("page sizes = %v, want [100 100 50]", pages)
+	}
+
+	if total != 250 || client.calls != 3 {
+		t.Errorf("total = %d over %d calls, want 250 over 3", total, client.calls)
+	}
+
+	if len(*listed) != 0 {
+		t.Errorf("streamed listing returned %d resources, want none", len(*listed))
+	}
+}
+
+func TestListStackResourcePagesAccumulatesWithoutCallback(t *testing.T) {
+	client := &fakeStackResourcesClient{resources: testResources(250), pageSize: 100}
+
+	listed, err := listStackResourcePages(context.Background(), client, "stack", nil)
+	if err != nil {
+		t.Fatalf("listStackResourcePages error: %v", err)
+	}
+
+	if len(*listed) != 250 {
+		t.Errorf("listed %d resources, want 250", len(*listed))
+	}
+}
+
+func TestListStackResourcePagesStopsOnCallbackError(t *testing.T) {
+	client := &fakeStackResourcesClient{resources: testResources(250), pageSize: 100}
+
+	_, err := listStackResourcePages(context.Background(), client, "stack", func([]cfTypes.StackResourceSummary) error {
+		return fmt.Errorf("consumer gone")
+	})
+	if err == nil || client.calls != 1 {
+		t.Errorf("error = %v after %d calls, want an error after 1 call", err, client.calls)
+	}
+}
+
+func TestListStackResourcePagesReturnsAPIError(t *testing.T) {
+	denied := errors.New("AccessDenied: not authorized to perform cloudformation:ListStackResources")
+	client := &fakeStackResourcesClient{resources: testResources(1), pageSize: 100, err: denied}
+
+	if _, err := listStackResourcePages(context.Background(), client, "stack", nil); !errors.Is(err, denied) {
+		t.Errorf("error = %v, want the wrapped ListStackResources error", err)
+	}
+}
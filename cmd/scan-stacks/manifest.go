@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// ManifestBOMFormat and ManifestSpecVersion identify the CycloneDX format the manifest follows.
+	ManifestBOMFormat   = "CycloneDX"
+	ManifestSpecVersion = "1.5"
+	// ManifestComponentType is the CycloneDX component type used for cloud resources.
+	ManifestComponentType = "platform"
+	// ManifestToolName is the tool recorded in the manifest metadata.
+	ManifestToolName = "scan-stacks"
+)
+
+// Property names of the manifest components.
+const (
+	ManifestPropertyAccount            = "aws:account"
+	ManifestPropertyRegion             = "aws:region"
+	ManifestPropertyResourceType       = "aws:cloudformation:resource-type"
+	ManifestPropertyPhysicalResourceID = "aws:cloudformation:physical-resource-id"
+	ManifestPropertyStackName          = "aws:cloudformation:stack-name"
+	ManifestPropertyStackID            = "aws:cloudformation:stack-id"
+)
+
+// resourceManifest is a CycloneDX-like inventory of the managed resources.
+type resourceManifest struct {
+	BOMFormat   string              `json:"bomFormat"`
+	SpecVersion string              `json:"specVersion"`
+	Version     int                 `json:"version"`
+	Metadata    manifestMetadata    `json:"metadata"`
+	Components  []manifestComponent `json:"components"`
+}
+
+// manifestMetadata records when and by what the manifest was generated.
+type manifestMetadata struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Tools     []manifestTool `json:"tools"`
+}
+
+// manifestTool is a tool that generated the manifest.
+type manifestTool struct {
+	Name string `json:"name"`
+}
+
+// manifestComponent is one managed resource.
+type manifestComponent struct {
+	BOMRef     string             `json:"bom-ref"`
+	Type       string             `json:"type"`
+	Name       string             `json:"name"`
+	Properties []manifestProperty `json:"properties"`
+}
+
+// manifestProperty is a name/value pair of a component.
+type manifestProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// buildManifest lists every resource of the report as a manifest component, with its type, physical id,
+// account, region and owning stack.
+func buildManifest(report *scanReport) resourceManifest {
+	manifest := resourceManifest{
+		BOMFormat:   ManifestBOMFormat,
+		SpecVersion: ManifestSpecVersion,
+		Version:     1,
+		Metadata: manifestMetadata{
+			Timestamp: report.GeneratedAt.UTC(),
+			Tools:     []manifestTool{{Name: ManifestToolName}},
+		},
+		Components: []manifestComponent{},
+	}
+
+	for _, region := range report.Regions {
+		for _, stack := range region.Stacks {
+			stackName := aws.ToString(stack.StackName)
+			stackID := aws.ToString(stack.StackID)
+
+			for _, resource := range stack.Resources {
+				logicalID := aws.ToString(resource.LogicalResourceID)
+
+				manifest.Components = append(manifest.Components, manifestComponent{
+					BOMRef: fmt.Sprintf("%s/%s/%s/%s", region.Account, region.Region, stackName, logicalID),
+					Type:   ManifestComponentType,
+					Name:   logicalID,
+					Properties: []manifestProperty{
+						{Name: ManifestPropertyResourceType, Value: aws.ToString(resource.ResourceType)},
+						{Name: ManifestPropertyPhysicalResourceID, Value: aws.ToString(resource.PhysicalResourceID)},
+						{Name: ManifestPropertyAccount, Value: region.Account},
+						{Name: ManifestPropertyRegion, Value: region.Region},
+						{Name: ManifestPropertyStackName, Value: stackName},
+						{Name: ManifestPropertyStackID, Value: stackID},
+					},
+				})
+			}
+		}
+	}
+
+	return manifest
+}
+
+// writeManifest writes the manifest of the report as indented JSON.
+func writeManifest(w io.Writer, report *scanReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(buildManifest(report)); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
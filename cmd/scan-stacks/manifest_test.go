@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestManifestComponents(t *testing.T) {
+	captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	queue := testStack("us-east-1", "queue", cfTypes.StackStatusUpdateComplete)
+	empty := testStack("us-east-1", "empty", cfTypes.StackStatusCreateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "us-east-1"},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web}, "us-east-1": {queue, empty}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				testResource("Role", "AWS::IAM::Role", "web-role"),
+			},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "https://sqs.us-east-1.amazonaws.com/111111111111/jobs")},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-manifest", "manifest.json")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	s.report.GeneratedAt = testTime
+
+	var got bytes.Buffer
+	if err := writeManifest(&got, s.report); err != nil {
+		t.Fatalf("writeManifest error: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "manifest.json"))
+	if err != nil {
+		t.Fatalf("reading expected manifest: %v", err)
+	}
+
+	if got.String() != string(want) {
+		t.Errorf("manifest =\n%s\nwant\n%s", got.String(), want)
+	}
+}
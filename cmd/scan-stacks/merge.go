@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Keys used to detect the same stack when merging reports, selected with -dedup-key.
+const (
+	// DedupKeyStackID treats stacks with the same StackId as one. The StackId ARN encodes the
+	// partition, region and account, so it identifies a stack canonically.
+	DedupKeyStackID = "stack-id"
+	// DedupKeyName treats stacks with the same account, region and name as one.
+	DedupKeyName = "name"
+	// DedupKeyNone keeps every stack.
+	DedupKeyNone = "none"
+)
+
+// validateDedupKey returns an error when key is not a known dedup key.
+func validateDedupKey(key string) error {
+	switch key {
+	case DedupKeyStackID, DedupKeyName, DedupKeyNone:
+		return nil
+	default:
+		return fmt.Errorf("dedup key must be one of %s, %s or %s: %s", DedupKeyStackID, DedupKeyName, DedupKeyNone, key)
+	}
+}
+
+// stackDedupKey returns the identity of stack under key, or "" when the stack must always be kept.
+func stackDedupKey(key string, region regionReport, stack stackReport) string {
+	switch key {
+	case DedupKeyStackID:
+		return aws.ToString(stack.StackID)
+	case DedupKeyName:
+		return region.Account + "/" + region.Region + "/" + aws.ToString(stack.StackName)
+	default:
+		return ""
+	}
+}
+
+// mergeReports merges reports into one, combining entries for the same account and region and
+// keeping only the first occurrence of each stack under key, so overlapping scans (e.g. org-wide
+// merges) do not double count stacks.
+func mergeReports(key string, reports ...*scanReport) *scanReport {
+	merged := &scanReport{Regions: []regionReport{}}
+	regionIndex := map[string]int{}
+	seen := map[string]bool{}
+
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+
+		if report.GeneratedAt.After(merged.GeneratedAt) {
+			merged.GeneratedAt = report.GeneratedAt
+		}
+
+		for _, region := range report.Regions {
+			regionKey := region.Account + "/" + region.Region
+
+			index, ok := regionIndex[regionKey]
+			if !ok {
+				index = len(merged.Regions)
+				regionIndex[regionKey] = index
+				merged.Regions = append(merged.Regions, regionReport{
+					Account:           region.Account,
+					Region:            region.Region,
+					AvailabilityZones: region.AvailabilityZones,
+					Stacks:            []stackReport{},
+				})
+			}
+
+			for _, stack := range region.Stacks {
+				stackKey := stackDedupKey(key, region, stack)
+				if stackKey != "" {
+					if seen[stackKey] {
+						continue
+					}
+
+					seen[stackKey] = true
+				}
+
+				merged.Regions[index].Stacks = append(merged.Regions[index].Stacks, stack)
+			}
+		}
+	}
+
+	return merged
+}
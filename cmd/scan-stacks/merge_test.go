@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// overlappingReports returns two scans of the same account that both saw the stack app in us-west-2.
+// The second scan also saw db after it was deleted and recreated with a new StackId, and a stack app in us-east-1.
+func overlappingReports() []*scanReport {
+	stack := func(region string, name string) stackReport {
+		return newStackReport(testStack(region, name, cfTypes.StackStatusCreateComplete))
+	}
+
+	recreated := stack(testDefaultRegion, "db")
+	recreated.StackID = aws.String(*recreated.StackID + "-recreated")
+
+	return []*scanReport{
+		{
+			GeneratedAt: testTime,
+			Regions: []regionReport{
+				{Account: testAccount, Region: testDefaultRegion, Stacks: []stackReport{stack(testDefaultRegion, "app"), stack(testDefaultRegion, "db")}},
+			},
+		},
+		nil,
+		{
+			GeneratedAt: testTime.Add(time.Hour),
+			Regions: []regionReport{
+				{Account: testAccount, Region: testDefaultRegion, Stacks: []stackReport{stack(testDefaultRegion, "app"), recreated}},
+				{Account: testAccount, Region: "us-east-1", Stacks: []stackReport{stack("us-east-1", "app")}},
+			},
+		},
+	}
+}
+
+func TestMergeReportsDedupsOverlaps(t *testing.T) {
+	tests := []struct {
+		key  string
+		want map[string][]string
+	}{
+		{DedupKeyStackID, map[string][]string{
+			testDefaultRegion: {"app/id", "db/id", "db/id-recreated"},
+			"us-east-1":       {"app/id"},
+		}},
+		{DedupKeyName, map[string][]string{
+			testDefaultRegion: {"app/id", "db/id"},
+			"us-east-1":       {"app/id"},
+		}},
+		{DedupKeyNone, map[string][]string{
+			testDefaultRegion: {"app/id", "db/id", "app/id", "db/id-recreated"},
+			"us-east-1":       {"app/id"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			merged := mergeReports(tt.key, overlappingReports()...)
+
+			if !merged.GeneratedAt.Equal(testTime.Add(time.Hour)) {
+				t.Errorf("GeneratedAt = %v, want the latest scan time", merged.GeneratedAt)
+			}
+
+			if len(merged.Regions) != len(tt.want) {
+				t.Fatalf("regions = %+v, want %d", merged.Regions, len(tt.want))
+			}
+
+			for _, region := range merged.Regions {
+				var stacks []string
+				for _, stack := range region.Stacks {
+					prefix := "arn:aws:cloudformation:" + region.Region + ":" + testAccount + ":stack/"
+					stacks = append(stacks, strings.TrimPrefix(aws.ToString(stack.StackID), prefix))
+				}
+
+				if !slices.Equal(stacks, tt.want[region.Region]) {
+					t.Errorf("%s stacks = %v, want %v", region.Region, stacks, tt.want[region.Region])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDedupKey(t *testing.T) {
+	if _, err := parseOptions("scan-stacks", []string{"-dedup-key", "arn"}); err == nil {
+		t.Error("-dedup-key arn returned no error")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// missingDependencyPatterns match ResourceStatusReason texts that blame a missing dependency.
+// The first capture group, when present, is the name of the missing dependency.
+var missingDependencyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`No export named (\S+) found`),
+	regexp.MustCompile(`Unresolved resource dependencies \[([^\]]+)\]`),
+	regexp.MustCompile(`'([^']+)' does not exist`),
+	regexp.MustCompile(`"([^"]+)" does not exist`),
+	regexp.MustCompile(`(?i)\b(\S+) (?:does not exist|was not found|not found|cannot be found)`),
+	regexp.MustCompile(`(?i)\b(?:does not exist|not found|cannot be found|no such)\b`),
+}
+
+// missingDependencyResource is a failed resource whose status reason names a missing dependency.
+type missingDependencyResource struct {
+	Account           string
+	Region            string
+	StackName         string
+	LogicalResourceID string
+	ResourceType      string
+	Dependency        string
+	Reason            string
+}
+
+// missingDependency returns the missing dependency named in reason, or the reason itself when
+// it blames a missing dependency without naming it. ok is false when reason does not match.
+func missingDependency(reason string) (string, bool) {
+	for _, pattern := range missingDependencyPatterns {
+		match := pattern.FindStringSubmatch(reason)
+		if match == nil {
+			continue
+		}
+
+		if len(match) > 1 && match[1] != "" {
+			return strings.Trim(match[1], ".,;:"), true
+		}
+
+		return reason, true
+	}
+
+	return "", false
+}
+
+// findMissingDependency returns the missing dependency entry for a failed resource, or nil.
+func findMissingDependency(account string, region string, stack cfTypes.StackSummary, resource cfTypes.StackResourceSummary) *missingDependencyResource {
+	if !strings.HasSuffix(string(resource.ResourceStatus), "_FAILED") {
+		return nil
+	}
+
+	reason := aws.ToString(resource.ResourceStatusReason)
+
+	dependency, ok := missingDependency(reason)
+	if !ok {
+		return nil
+	}
+
+	return &missingDependencyResource{
+		Account:           account,
+		Region:            region,
+		StackName:         aws.ToString(stack.StackName),
+		LogicalResourceID: aws.ToString(resource.LogicalResourceId),
+		ResourceType:      aws.ToString(resource.ResourceType),
+		Dependency:        dependency,
+		Reason:            reason,
+	}
+}
+
+// groupMissingDependencies groups the failed resources by the dependency they are missing.
+func groupMissingDependencies(resources []missingDependencyResource) map[string][]missingDependencyResource {
+	groups := map[string][]missingDependencyResource{}
+
+	for _, resource := range resources {
+		groups[resource.Dependency] = append(groups[resource.Dependency], resource)
+	}
+
+	return groups
+}
+
+// printMissingDependencyReport logs the failed resources grouped by missing dependency.
+func printMissingDependencyReport(resources []missingDependencyResource) {
+	groups := groupMissingDependencies(resources)
+
+	dependencies := make([]string, 0, len(groups))
+	for dependency := range groups {
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.Strings(dependencies)
+
+	log.Printf("Resources failed on missing dependencies: %d (%d dependencies)\n", len(resources), len(dependencies))
+
+	for _, dependency := range dependencies {
+		log.Printf("- Missing: %s", dependency)
+
+		for _, resource := range groups[dependency] {
+			log.Printf("  - %s/%s (%s, %s): %s", resource.StackName, resource.LogicalResourceID, resource.ResourceType,
+				resource.Region, resource.Reason)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// missingDepsReasons reads the reason fixture: each reason with the dependency it is missing, "" when none.
+func missingDepsReasons(t *testing.T) [][2]string {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", "missing-deps-reasons.txt"))
+	if err != nil {
+		t.Fatalf("opening reason fixture: %v", err)
+	}
+	defer file.Close()
+
+	var reasons [][2]string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" && !strings.HasPrefix(line, "#") {
+			reason, dependency, _ := strings.Cut(line, "\t")
+			reasons = append(reasons, [2]string{reason, dependency})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading reason fixture: %v", err)
+	}
+
+	return reasons
+}
+
+func TestMissingDependencyFromReason(t *testing.T) {
+	for _, fixture := range missingDepsReasons(t) {
+		reason, want := fixture[0], fixture[1]
+
+		dependency, ok := missingDependency(reason)
+		if ok != (want != "") || dependency != want {
+			t.Errorf("missingDependency(%q) = %q, %v, want %q", reason, dependency, ok, want)
+		}
+	}
+}
+
+func TestMissingDepsReportGroupsFailedResources(t *testing.T) {
+	logs := captureLog(t)
+
+	resource := func(logicalID string, status cfTypes.ResourceStatus, reason string) cfTypes.StackResourceSummary {
+		r := testResource(logicalID, "AWS::EC2::Instance", "")
+		r.ResourceStatus = status
+		r.ResourceStatusReason = aws.String(reason)
+
+		return r
+	}
+
+	noExport := "No export named network-VpcId found. Rollback requested by user."
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusRollbackComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				resource("Server", cfTypes.ResourceStatusCreateFailed, noExport),
+				resource("Worker", cfTypes.ResourceStatusCreateFailed, noExport),
+				resource("Volume", cfTypes.ResourceStatusDeleteFailed, "Subnet subnet-0a1b2c3d was not found."),
+				// Only failed resources are reported, whatever their reason.
+				resource("Cache", cfTypes.ResourceStatusDeleteComplete, noExport),
+				resource("Queue", cfTypes.ResourceStatusCreateFailed, "Resource creation cancelled"),
+			},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-missing-deps")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	printMissingDependencyReport(s.missingDeps)
+
+	want := "Resources failed on missing dependencies: 3 (2 dependencies)\n" +
+		"- Missing: network-VpcId\n" +
+		"  - web/Server (AWS::EC2::Instance, us-west-2): " + noExport + "\n" +
+		"  - web/Worker (AWS::EC2::Instance, us-west-2): " + noExport + "\n" +
+		"- Missing: subnet-0a1b2c3d\n" +
+		"  - web/Volume (AWS::EC2::Instance, us-west-2): Subnet subnet-0a1b2c3d was not found.\n"
+	if !strings.HasSuffix(logs.String(), want) {
+		t.Errorf("report =\n%s\nwant it to end with\n%s", logs, want)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// noPhysicalIDResource is a resource without a physical resource id, i.e. one that is still being
+// created or failed before it was provisioned.
+type noPhysicalIDResource struct {
+	Account           string
+	Region            string
+	StackName         string
+	LogicalResourceID string
+	ResourceType      string
+	ResourceStatus    string
+	Reason            string
+}
+
+// findNoPhysicalID returns resource when it has no physical resource id, or nil otherwise.
+func findNoPhysicalID(account string, region string, stack cfTypes.StackSummary, resource cfTypes.StackResourceSummary) *noPhysicalIDResource {
+	if aws.ToString(resource.PhysicalResourceId) != "" {
+		return nil
+	}
+
+	return &noPhysicalIDResource{
+		Account:           account,
+		Region:            region,
+		StackName:         aws.ToString(stack.StackName),
+		LogicalResourceID: aws.ToString(resource.LogicalResourceId),
+		ResourceType:      aws.ToString(resource.ResourceType),
+		ResourceStatus:    string(resource.ResourceStatus),
+		Reason:            aws.ToString(resource.ResourceStatusReason),
+	}
+}
+
+// printNoPhysicalIDReport logs the resources without a physical resource id, which often are stuck creates.
+func printNoPhysicalIDReport(resources []noPhysicalIDResource) {
+	log.Printf("Resources without a physical resource id: %d\n", len(resources))
+
+	for _, resource := range resources {
+		log.Printf("- %s/%s (%s, %s, %s) %s: %s", resource.StackName, resource.LogicalResourceID, resource.ResourceType,
+			resource.Account, resource.Region, resource.ResourceStatus, resource.Reason)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestNoPhysicalIDOnlyReportsResourcesWithoutPhysicalID(t *testing.T) {
+	logs := captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateInProgress)
+	queue := testStack(testDefaultRegion, "queue", cfTypes.StackStatusCreateFailed)
+
+	creating := testResource("Distribution", "AWS::CloudFront::Distribution", "")
+	creating.ResourceStatus = cfTypes.ResourceStatusCreateInProgress
+
+	failed := testResource("Queue", "AWS::SQS::Queue", "")
+	failed.ResourceStatus = cfTypes.ResourceStatusCreateFailed
+	failed.ResourceStatusReason = aws.String("Resource handler returned message: \"Access denied\"")
+
+	// An empty physical id is as missing as a nil one.
+	empty := testResource("Topic", "AWS::SNS::Topic", "")
+	empty.PhysicalResourceId = aws.String("")
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId:   {testResource("Bucket", "AWS::S3::Bucket", "web-assets"), creating},
+			*queue.StackId: {failed, testResource("Role", "AWS::IAM::Role", "queue-role"), empty},
+		},
+	}
+	s := newTestScanner(t, account, false, "-no-physical-id", "-concurrency", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	var got []string
+	for _, resource := range s.noPhysicalID {
+		got = append(got, resource.StackName+"/"+resource.LogicalResourceID+" "+resource.ResourceStatus)
+	}
+
+	want := []string{"web/Distribution CREATE_IN_PROGRESS", "queue/Queue CREATE_FAILED", "queue/Topic CREATE_COMPLETE"}
+	if !slices.Equal(got, want) {
+		t.Errorf("resources without a physical id = %v, want %v", got, want)
+	}
+
+	printNoPhysicalIDReport(s.noPhysicalID)
+
+	for _, line := range []string{
+		"Resources without a physical resource id: 3\n",
+		"- queue/Queue (AWS::SQS::Queue, 111111111111, us-west-2) CREATE_FAILED: Resource handler returned message: \"Access denied\"\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OCSF Detection Finding class constants (OCSF schema 1.1.0).
+const (
+	OCSFVersion                = "1.1.0"
+	OCSFCategoryFindings       = 2
+	OCSFCategoryName           = "Findings"
+	OCSFClassDetectionFinding  = 2004
+	OCSFClassName              = "Detection Finding"
+	OCSFActivityCreate         = 1
+	OCSFActivityName           = "Create"
+	OCSFStatusNew              = 1
+	OCSFStatusName             = "New"
+	OCSFSeverityMedium         = 3
+	OCSFSeverityHigh           = 4
+	OCSFCloudProvider          = "AWS"
+	OCSFProductName            = "scan-stacks"
+	OCSFProductVendorName      = "aws-go-tools"
+	ocsfTypeUIDClassMultiplier = 100
+)
+
+// ocsfSeverityNames are the OCSF names of the severities used for stack findings.
+var ocsfSeverityNames = map[int]string{
+	OCSFSeverityMedium: "Medium",
+	OCSFSeverityHigh:   "High",
+}
+
+// ocsfDetectionFinding is an OCSF Detection Finding (class 2004) event.
+type ocsfDetectionFinding struct {
+	ActivityID   int                `json:"activity_id"`
+	ActivityName string             `json:"activity_name"`
+	CategoryUID  int                `json:"category_uid"`
+	CategoryName string             `json:"category_name"`
+	ClassUID     int                `json:"class_uid"`
+	ClassName    string             `json:"class_name"`
+	TypeUID      int                `json:"type_uid"`
+	SeverityID   int                `json:"severity_id"`
+	Severity     string             `json:"severity"`
+	StatusID     int                `json:"status_id"`
+	Status       string             `json:"status"`
+	Time         int64              `json:"time"`
+	Message      string             `json:"message"`
+	Metadata     ocsfMetadata       `json:"metadata"`
+	FindingInfo  ocsfFindingInfo    `json:"finding_info"`
+	Cloud        ocsfCloud          `json:"cloud"`
+	Resources    []ocsfResourceItem `json:"resources"`
+}
+
+// ocsfMetadata identifies the schema version and the product that produced the event.
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	Product ocsfProduct `json:"product"`
+}
+
+// ocsfProduct is the product that produced the event.
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+// ocsfFindingInfo describes the finding itself.
+type ocsfFindingInfo struct {
+	UID   string   `json:"uid"`
+	Title string   `json:"title"`
+	Desc  string   `json:"desc,omitempty"`
+	Types []string `json:"types"`
+}
+
+// ocsfCloud is the cloud account and region of the finding.
+type ocsfCloud struct {
+	Provider string      `json:"provider"`
+	Region   string      `json:"region"`
+	Account  ocsfAccount `json:"account"`
+}
+
+// ocsfAccount is a cloud account.
+type ocsfAccount struct {
+	UID string `json:"uid"`
+}
+
+// ocsfResourceItem is the resource the finding is about.
+type ocsfResourceItem struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Region string `json:"region"`
+}
+
+// newOCSFDetectionFinding maps a stack finding to an OCSF Detection Finding observed at now.
+func newOCSFDetectionFinding(finding stackFinding, now time.Time) ocsfDetectionFinding {
+	severityID := OCSFSeverityMedium
+	title := fmt.Sprintf("CloudFormation stack %s has drifted", finding.StackName)
+	message := fmt.Sprintf("Stack drift status is %s", finding.DriftStatus)
+
+	if finding.FindingType == FindingTypeFailed {
+		severityID = OCSFSeverityHigh
+		title = fmt.Sprintf("CloudFormation stack %s is in a failed state", finding.StackName)
+		message = fmt.Sprintf("Stack status is %s", finding.StackStatus)
+	}
+
+	return ocsfDetectionFinding{
+		ActivityID:   OCSFActivityCreate,
+		ActivityName: OCSFActivityName,
+		CategoryUID:  OCSFCategoryFindings,
+		CategoryName: OCSFCategoryName,
+		ClassUID:     OCSFClassDetectionFinding,
+		ClassName:    OCSFClassName,
+		TypeUID:      OCSFClassDetectionFinding*ocsfTypeUIDClassMultiplier + OCSFActivityCreate,
+		SeverityID:   severityID,
+		Severity:     ocsfSeverityNames[severityID],
+		StatusID:     OCSFStatusNew,
+		Status:       OCSFStatusName,
+		Time:         now.UnixMilli(),
+		Message:      message,
+		Metadata: ocsfMetadata{
+			Version: OCSFVersion,
+			Product: ocsfProduct{Name: OCSFProductName, VendorName: OCSFProductVendorName},
+		},
+		FindingInfo: ocsfFindingInfo{
+			UID:   fmt.Sprintf("%s:%s", finding.StackID, finding.FindingType),
+			Title: title,
+			Desc:  finding.StackStatusReason,
+			Types: []string{finding.FindingType},
+		},
+		Cloud: ocsfCloud{
+			Provider: OCSFCloudProvider,
+			Region:   finding.Region,
+			Account:  ocsfAccount{UID: finding.Account},
+		},
+		Resources: []ocsfResourceItem{{
+			UID:    finding.StackID,
+			Name:   finding.StackName,
+			Type:   NestedStackResourceType,
+			Region: finding.Region,
+		}},
+	}
+}
+
+// writeOCSFFindings writes the stack findings as a JSON array of OCSF Detection Findings.
+func writeOCSFFindings(w io.Writer, findings []stackFinding, now time.Time) error {
+	events := make([]ocsfDetectionFinding, 0, len(findings))
+	for _, finding := range findings {
+		events = append(events, newOCSFDetectionFinding(finding, now))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(events); err != nil {
+		return fmt.Errorf("failed to write OCSF findings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// ocsfRequiredFields are the attributes an OCSF 1.1.0 Detection Finding must have, as dotted paths.
+var ocsfRequiredFields = []string{
+	"activity_id", "category_uid", "class_uid", "type_uid", "severity_id", "time",
+	"metadata.version", "metadata.product.name", "metadata.product.vendor_name",
+	"finding_info.uid", "finding_info.title", "cloud.provider",
+}
+
+// jsonPath returns the value at the dotted path of a decoded JSON object, and whether it is set.
+func jsonPath(object map[string]any, path string) (any, bool) {
+	var value any = object
+
+	for _, name := range strings.Split(path, ".") {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = fields[name]; !ok || value == nil {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func TestOCSFFindingsHaveRequiredFields(t *testing.T) {
+	captureLog(t)
+
+	failed := testStack(testDefaultRegion, "broken", cfTypes.StackStatusUpdateRollbackFailed)
+	failed.StackStatusReason = aws.String("Resource Bucket failed to update")
+
+	drifted := testStack(testDefaultRegion, "drifted", cfTypes.StackStatusUpdateComplete)
+	drifted.DriftInformation = &cfTypes.StackDriftInformationSummary{StackDriftStatus: cfTypes.StackDriftStatusDrifted}
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks: map[string][]cfTypes.StackSummary{
+			testDefaultRegion: {failed, drifted, testStack(testDefaultRegion, "healthy", cfTypes.StackStatusCreateComplete)},
+		},
+	}
+	s := newTestScanner(t, account, false, "-output", "ocsf")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeOCSFFindings(&buf, s.findings, testTime); err != nil {
+		t.Fatalf("writeOCSFFindings error: %v", err)
+	}
+
+	var events []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("decoding OCSF findings %q: %v", buf.String(), err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("OCSF findings = %s, want one for broken and one for drifted", buf.String())
+	}
+
+	for _, event := range events {
+		for _, path := range ocsfRequiredFields {
+			if _, ok := jsonPath(event, path); !ok {
+				t.Errorf("OCSF finding %v has no %s", event["finding_info"], path)
+			}
+		}
+
+		if classUID, _ := jsonPath(event, "class_uid"); classUID != float64(OCSFClassDetectionFinding) {
+			t.Errorf("class_uid = %v, want %d", classUID, OCSFClassDetectionFinding)
+		}
+
+		if typeUID, _ := jsonPath(event, "type_uid"); typeUID != float64(200401) {
+			t.Errorf("type_uid = %v, want 200401", typeUID)
+		}
+
+		if eventTime, _ := jsonPath(event, "time"); eventTime != float64(testTime.UnixMilli()) {
+			t.Errorf("time = %v, want %d", eventTime, testTime.UnixMilli())
+		}
+
+		resources, _ := event["resources"].([]any)
+		if len(resources) != 1 {
+			t.Fatalf("resources = %v, want the stack", event["resources"])
+		}
+
+		if resource := resources[0].(map[string]any); resource["type"] != NestedStackResourceType || resource["region"] != testDefaultRegion {
+			t.Errorf("resource = %v, want a %s in %s", resource, NestedStackResourceType, testDefaultRegion)
+		}
+	}
+
+	if severity, _ := jsonPath(events[0], "severity"); severity != "High" {
+		t.Errorf("severity of the failed stack = %v, want High", severity)
+	}
+
+	if desc, _ := jsonPath(events[0], "finding_info.desc"); desc != "Resource Bucket failed to update" {
+		t.Errorf("finding_info.desc = %v, want the stack status reason", desc)
+	}
+
+	if severity, _ := jsonPath(events[1], "severity"); severity != "Medium" {
+		t.Errorf("severity of the drifted stack = %v, want Medium", severity)
+	}
+}
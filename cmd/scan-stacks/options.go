@@ -0,0 +1,370 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// options holds the command line settings for scan-stacks.
+type options struct {
+	// driftStale, when non-zero, reports stacks whose last drift check is older than this duration.
+	driftStale time.Duration
+	// eventBusName, when set, sends failed and drifted stack findings to this EventBridge bus.
+	eventBusName string
+	// listAPIs prints the AWS API operations the other options would invoke, then exits.
+	listAPIs bool
+	// tfStatePath, when set, cross-references scanned resources against this Terraform state file.
+	tfStatePath string
+	// stackName selects a single stack, by name or id, for the single-stack modes.
+	stackName string
+	// outputsAsEnv prints the outputs of stackName as shell export lines, then exits.
+	outputsAsEnv bool
+	// waitForStable polls stackName until it reaches a terminal status, then exits with a code reflecting it.
+	waitForStable bool
+	// waitInterval is the delay between polls in waitForStable mode.
+	waitInterval time.Duration
+	// waitTimeout bounds the wait in waitForStable mode; zero waits for as long as the stack takes.
+	waitTimeout time.Duration
+	// serviceMap prints each scanned resource type with its AWS service and scope after the scan.
+	serviceMap bool
+	// shard restricts the scan to the account/region pairs that hash into it.
+	shard shard
+	// emitEventsPath, when set, writes length-prefixed JSON scan events to this file ("-" for stdout).
+	emitEventsPath string
+	// org scans every active account of the AWS Organization instead of only the caller's account.
+	org bool
+	// orgRole is the role assumed in each member account in org mode.
+	orgRole string
+	// output is the output format: text (logged while scanning), json, csv, ocsf or canonical (written to stdout after the scan).
+	output string
+	// nilPlaceholder overrides the output format's placeholder for missing values when nilPlaceholderSet.
+	nilPlaceholder    string
+	nilPlaceholderSet bool
+	// rootCause walks failed root stacks into their nested stacks' events to report the originating failure.
+	rootCause bool
+	// minRPS and maxRPS bound the adaptive throttle of resource listing; maxRPS 0 disables it.
+	minRPS float64
+	maxRPS float64
+	// inProgressCount only counts stacks with an operation in progress, then exits non-zero if there are any.
+	inProgressCount bool
+	// resourceTemplate, when set, is a Go template printed to stdout for every scanned resource.
+	resourceTemplate string
+	// azs lists the availability zones of each scanned region.
+	azs bool
+	// genPolicy prints a least-privilege IAM policy for the other options, then exits.
+	genPolicy bool
+	// dedupKey selects how duplicate stacks are detected when the report is merged.
+	dedupKey string
+	// checkSkew, when non-zero, warns when the local clock differs from AWS time by more than this.
+	checkSkew time.Duration
+	// statusCounts counts each stack's resources by status, in the JSON report or as a text table.
+	statusCounts bool
+	// interactive opens a fuzzy-searchable stack and resource picker after the scan when run in a terminal.
+	interactive bool
+	// missingDeps reports failed resources whose status reason blames a missing dependency.
+	missingDeps bool
+	// concurrency is the number of regions and of stacks listed at the same time, and the depth of the queues between them.
+	concurrency int
+	// manifestPath, when set, writes a CycloneDX-like manifest of the scanned resources to this file ("-" for stdout).
+	manifestPath string
+	// explain appends a short explanation to each status in text output.
+	explain bool
+	// kafkaBrokers and kafkaTopic, when set, publish a message per scanned stack and resource to Kafka.
+	kafkaBrokers []string
+	kafkaTopic   string
+	// affectedBy, when set, lists the stacks containing resources of this type (path.Match pattern).
+	affectedBy string
+	// cacheDir, when set, caches the collected report in this directory for cacheTTL, keyed by the options and credentials.
+	cacheDir string
+	cacheTTL time.Duration
+	// resourceTimeline reconstructs each resource's creation and update times from its stack's events.
+	resourceTimeline bool
+	// showCredsSource logs the credentials provider that resolved for each scanned account and region.
+	showCredsSource bool
+	// baselinePath, when set, compares the scan against this JSON report and reports the deviations.
+	baselinePath string
+	// failOnDeviation exits non-zero when the scan deviates from the baseline.
+	failOnDeviation bool
+	// stackSetOrigin reports the StackSet that deployed each stack, read from its stack tags.
+	stackSetOrigin bool
+	// groupByTag, when set, groups the scanned stacks by the value of this tag, e.g. team or owner.
+	groupByTag string
+	// policyPath, when set, evaluates the rules of this policy file against every scanned resource.
+	policyPath string
+	// showEndpoints logs the resolved CloudFormation endpoint of each region before scanning.
+	showEndpoints bool
+	// dualStack sends every AWS request to the dual-stack (IPv4 and IPv6) endpoints.
+	dualStack bool
+	// maxDepth, when positive, limits how many levels of nested stacks the root cause analysis walks.
+	maxDepth int
+	// noPhysicalID reports the resources without a physical resource id.
+	noPhysicalID bool
+	// aggregatePath, when set, writes the stack and resource counts over time of the prior JSON reports
+	// matching this glob, then exits without scanning.
+	aggregatePath string
+	// onlyRegionsWithStacks leaves the regions without stacks out of the output, noting them as scanned.
+	onlyRegionsWithStacks bool
+	// sharedResource, when set, lists every stack with a resource referencing this physical id.
+	sharedResource string
+	// sharedResourceType, when set, restricts sharedResource to the resources of this type, e.g. AWS::S3::Bucket.
+	sharedResourceType string
+}
+
+// parseOptions parses the command line arguments (without the program name) into options.
+func parseOptions(name string, args []string) (*options, error) {
+	opts := options{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.DurationVar(&opts.driftStale, "drift-stale", 0,
+		"report stacks whose last drift check is older than this duration, or that were never checked (e.g. 168h)")
+	fs.StringVar(&opts.eventBusName, "eventbridge", "",
+		"send failed and drifted stack findings to this EventBridge bus name or ARN")
+	fs.BoolVar(&opts.listAPIs, "list-apis", false,
+		"print every AWS API operation scan-stacks may invoke with the other options, then exit")
+	fs.StringVar(&opts.tfStatePath, "tf-state", "",
+		"cross-reference scanned resources against the resource ids in this Terraform state file")
+	fs.StringVar(&opts.stackName, "stack", "",
+		"name or id of the stack used by the single-stack modes (-outputs-as-env, -wait-for-stable)")
+	fs.BoolVar(&opts.outputsAsEnv, "outputs-as-env", false,
+		"print the outputs of -stack as 'export KEY=VALUE' lines, then exit")
+	fs.BoolVar(&opts.waitForStable, "wait-for-stable", false,
+		"poll -stack until it reaches a terminal status, then exit non-zero if the operation failed")
+	fs.DurationVar(&opts.waitInterval, "wait-interval", DefaultWaitInterval,
+		"delay between polls in -wait-for-stable mode")
+	fs.DurationVar(&opts.waitTimeout, "wait-timeout", 0,
+		"give up -wait-for-stable after this long, e.g. for a stack left in REVIEW_IN_PROGRESS (default 0, no limit)")
+	fs.BoolVar(&opts.serviceMap, "service-map", false,
+		"after the scan, print each unique resource type with its AWS service and whether it is regional or global")
+	fs.Func("shard", "only scan the account/region pairs whose hash mod N equals i (form i/N), to split a scan across jobs",
+		func(value string) error {
+			parsed, err := parseShard(value)
+			if err != nil {
+				return err
+			}
+
+			opts.shard = parsed
+
+			return nil
+		})
+	fs.StringVar(&opts.emitEventsPath, "emit-events", "",
+		"write length-prefixed JSON scan events to this file or pipe (\"-\" for stdout, unless another option writes to stdout) "+
+			"for an incremental frontend")
+	fs.BoolVar(&opts.org, "org", false,
+		"scan every active account of the AWS Organization (requires organizations:ListAccounts), assuming -org-role in each")
+	fs.StringVar(&opts.orgRole, "org-role", DefaultOrgRole,
+		"name of the IAM role to assume in each member account in -org mode")
+	fs.StringVar(&opts.output, "output", OutputText,
+		"output format: text, json, csv, ocsf (OCSF Detection Findings for failed and drifted stacks) or canonical (sorted, diffable text)")
+	fs.Func("nil-placeholder", "text printed for missing values in text, csv and canonical output (default \"<nil>\" for text, empty for csv, \"-\" for canonical)",
+		func(value string) error {
+			opts.nilPlaceholder = value
+			opts.nilPlaceholderSet = true
+
+			return nil
+		})
+	fs.BoolVar(&opts.rootCause, "root-cause", false,
+		"for failed root stacks, walk nested stack events to report the originating resource and reason")
+	fs.Float64Var(&opts.maxRPS, "max-rps", 0,
+		"adaptively throttle stack resource listing to at most this many requests per second (0 disables)")
+	fs.Float64Var(&opts.minRPS, "min-rps", DefaultMinRPS,
+		"lowest request rate the adaptive throttle backs off to when CloudFormation throttles requests")
+	fs.BoolVar(&opts.inProgressCount, "in-progress-count", false,
+		"only count stacks with an operation in progress (*_IN_PROGRESS) across regions; exit non-zero if any")
+	fs.StringVar(&opts.resourceTemplate, "output-template-per-resource", "",
+		"Go template printed for each resource as it is scanned, e.g. '{{.StackName}} {{.ResourceType}} {{str .PhysicalResourceId}}'")
+	fs.BoolVar(&opts.azs, "azs", false,
+		"list the availability zones of each scanned region (one extra EC2 call per region)")
+	fs.BoolVar(&opts.genPolicy, "gen-policy", false,
+		"print a least-privilege IAM policy document for the other options, then exit")
+	fs.StringVar(&opts.dedupKey, "dedup-key", DedupKeyStackID,
+		"how duplicate stacks are detected when merging the report: stack-id, name or none")
+	fs.DurationVar(&opts.checkSkew, "check-skew", 0,
+		"warn when the local clock differs from the STS response Date by more than this duration (e.g. 30s)")
+	fs.BoolVar(&opts.statusCounts, "status-counts", false,
+		"count each stack's resources by status (resourceStatusCounts in JSON, a compact table in text output)")
+	fs.BoolVar(&opts.interactive, "interactive", false,
+		"after the scan, fuzzy-search stacks and resources and print the details of the selected one; "+
+			"without a terminal, list them instead")
+	fs.BoolVar(&opts.missingDeps, "missing-deps", false,
+		"report failed resources whose status reason points to a missing dependency, grouped by dependency")
+	fs.IntVar(&opts.concurrency, "concurrency", DefaultConcurrency,
+		"number of regions and of stacks listed at the same time; also bounds the queued work between them")
+	fs.StringVar(&opts.manifestPath, "manifest", "",
+		"write a CycloneDX-like JSON manifest of the scanned resources to this file (\"-\" for stdout)")
+	fs.BoolVar(&opts.explain, "explain", false,
+		"append a short explanation to each stack and resource status in text output")
+	fs.Func("kafka", "publish a JSON message per scanned stack and resource to a Kafka topic (form broker[,broker...],topic)",
+		func(value string) error {
+			brokers, topic, err := parseKafkaTarget(value)
+			if err != nil {
+				return err
+			}
+
+			opts.kafkaBrokers = brokers
+			opts.kafkaTopic = topic
+
+			return nil
+		})
+	fs.StringVar(&opts.affectedBy, "affected-by", "",
+		"list the stacks containing resources of this type, e.g. AWS::EC2::SecurityGroup or AWS::EC2::*, to review before a change")
+	fs.StringVar(&opts.cacheDir, "cache-dir", "",
+		"cache the collected report in this directory, so rendering it again (e.g. in another -output) skips the scan")
+	fs.DurationVar(&opts.cacheTTL, "cache-ttl", DefaultCacheTTL,
+		"how long a cached report is reused with -cache-dir")
+	fs.BoolVar(&opts.resourceTimeline, "resource-timeline", false,
+		"reconstruct each resource's creation and update times from its stack's events (one extra call per stack)")
+	fs.BoolVar(&opts.showCredsSource, "show-creds-source", false,
+		"log which credentials provider (env, shared config, assume role, SSO...) resolved for each account and region")
+	fs.StringVar(&opts.baselinePath, "compare-to-baseline", "",
+		"compare the scan against this known-good JSON report (-output json) and report new, removed and regressed stacks")
+	fs.BoolVar(&opts.failOnDeviation, "fail-on-deviation", false,
+		"exit non-zero when the scan deviates from -compare-to-baseline")
+	fs.BoolVar(&opts.stackSetOrigin, "stackset-origin", false,
+		"report the StackSet that deployed each stack, from its aws:cloudformation:stackset-id tag")
+	fs.StringVar(&opts.groupByTag, "group-by-tag", "",
+		"group the scanned stacks by the value of this tag (e.g. team or owner), with an \"untagged\" group")
+	fs.StringVar(&opts.policyPath, "policy", "",
+		"evaluate the rules of this policy file against every resource and report violations, "+
+			"e.g. 'prod-ec2: when type == \"AWS::EC2::Instance\" require stack.tag.env == prod'")
+	fs.BoolVar(&opts.showEndpoints, "show-endpoints", false,
+		"log the CloudFormation endpoint resolved for each region (endpoint overrides, FIPS, dual-stack) before scanning")
+	fs.BoolVar(&opts.dualStack, "dualstack", false,
+		"use the dual-stack (IPv4 and IPv6) endpoints of every AWS service, e.g. on IPv6-only networks")
+	fs.IntVar(&opts.maxDepth, "max-depth", 0,
+		"walk at most this many levels of nested stacks in -root-cause, marking the analysis truncated (0 for no limit)")
+	fs.BoolVar(&opts.noPhysicalID, "no-physical-id", false,
+		"report resources without a physical resource id (still being created or failed before provisioning), often stuck creates")
+	fs.StringVar(&opts.aggregatePath, "aggregate", "",
+		"read the prior JSON reports (-output json) matching this glob and write the stack and resource counts per "+
+			"account and region over time (table, json or csv), without scanning")
+	fs.BoolVar(&opts.onlyRegionsWithStacks, "only-regions-with-stacks", false,
+		"leave regions without stacks out of the output, listing them once as scanned (regionsWithoutStacks in JSON, "+
+			"account and region only rows in CSV)")
+	fs.StringVar(&opts.sharedResource, "shared-resource", "",
+		"list every stack, across the scanned regions, with a resource referencing this physical id (id, name or ARN)")
+	fs.StringVar(&opts.sharedResourceType, "shared-resource-type", "",
+		"with -shared-resource, only list the resources of this type, e.g. AWS::S3::Bucket for a name other types share")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if opts.checkSkew < 0 {
+		return nil, fmt.Errorf("-check-skew must not be negative: %s", opts.checkSkew)
+	}
+
+	if opts.driftStale < 0 {
+		return nil, fmt.Errorf("-drift-stale must not be negative: %s", opts.driftStale)
+	}
+
+	if opts.failOnDeviation && opts.baselinePath == "" {
+		return nil, fmt.Errorf("-fail-on-deviation requires -compare-to-baseline")
+	}
+
+	if opts.maxDepth < 0 {
+		return nil, fmt.Errorf("-max-depth must not be negative: %d", opts.maxDepth)
+	}
+
+	if opts.cacheTTL <= 0 {
+		return nil, fmt.Errorf("-cache-ttl must be positive: %s", opts.cacheTTL)
+	}
+
+	if opts.outputsAsEnv && opts.stackName == "" {
+		return nil, fmt.Errorf("-outputs-as-env requires -stack")
+	}
+
+	if opts.waitForStable && opts.stackName == "" {
+		return nil, fmt.Errorf("-wait-for-stable requires -stack")
+	}
+
+	if opts.waitInterval <= 0 {
+		return nil, fmt.Errorf("-wait-interval must be positive: %s", opts.waitInterval)
+	}
+
+	if opts.waitTimeout < 0 {
+		return nil, fmt.Errorf("-wait-timeout must not be negative: %s", opts.waitTimeout)
+	}
+
+	if opts.concurrency <= 0 {
+		return nil, fmt.Errorf("-concurrency must be positive: %d", opts.concurrency)
+	}
+
+	if opts.maxRPS < 0 || (opts.maxRPS > 0 && (opts.minRPS <= 0 || opts.minRPS > opts.maxRPS)) {
+		return nil, fmt.Errorf("-min-rps must be positive and not above -max-rps: %.2f, %.2f", opts.minRPS, opts.maxRPS)
+	}
+
+	if opts.affectedBy != "" {
+		if err := validateResourceTypePattern(opts.affectedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateDedupKey(opts.dedupKey); err != nil {
+		return nil, err
+	}
+
+	switch opts.output {
+	case OutputText, OutputJSON, OutputCSV, OutputOCSF, OutputCanonical:
+	default:
+		return nil, fmt.Errorf("-output must be one of text, json, csv, ocsf or canonical: %s", opts.output)
+	}
+
+	if opts.emitEventsPath == "-" {
+		if flag := opts.stdoutFlag(); flag != "" {
+			return nil, fmt.Errorf("-emit-events - cannot share stdout with %s; write the events to a file or named pipe", flag)
+		}
+	}
+
+	if opts.sharedResourceType != "" && opts.sharedResource == "" {
+		return nil, fmt.Errorf("-shared-resource-type requires -shared-resource")
+	}
+
+	if opts.aggregatePath != "" && (opts.output == OutputOCSF || opts.output == OutputCanonical) {
+		return nil, fmt.Errorf("-aggregate supports -output text, json or csv: %s", opts.output)
+	}
+
+	return &opts, nil
+}
+
+// stdoutFlag returns the first option that writes to stdout, or "" when stdout only receives the text log.
+func (o *options) stdoutFlag() string {
+	switch {
+	case o.output != OutputText:
+		return "-output " + o.output
+	case o.resourceTemplate != "":
+		return "-output-template-per-resource"
+	case o.manifestPath == "-":
+		return "-manifest -"
+	case o.inProgressCount:
+		return "-in-progress-count"
+	case o.statusCounts:
+		return "-status-counts"
+	case o.serviceMap:
+		return "-service-map"
+	case o.interactive:
+		return "-interactive"
+	case o.outputsAsEnv:
+		return "-outputs-as-env"
+	default:
+		return ""
+	}
+}
+
+// needsStackTags reports whether the scan reads the stack tags, which takes a DescribeStacks listing per region.
+func (o *options) needsStackTags() bool {
+	return o.stackSetOrigin || o.groupByTag != "" || o.policyPath != ""
+}
+
+// formatter returns the formatter for the selected output format and nil placeholder.
+func (o *options) formatter() formatter {
+	placeholder := defaultNilPlaceholder(o.output)
+	if o.nilPlaceholderSet && o.output != OutputJSON && o.output != OutputOCSF {
+		placeholder = o.nilPlaceholder
+	}
+
+	f := newFormatter(o.output, placeholder)
+	f.explain = o.explain && o.output == OutputText
+
+	return f
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// DefaultOrgRole is the role assumed in each member account in -org mode, created by AWS Organizations by default.
+const DefaultOrgRole = "OrganizationAccountAccessRole"
+
+// organizationsAPI is the subset of the AWS Organizations client used by scan-stacks.
+type organizationsAPI interface {
+	ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+// scanTarget is an account to scan together with the configuration holding its credentials.
+// When RoleARN is set, the account is scanned by assuming RoleARN with the Config credentials.
+type scanTarget struct {
+	AccountID string
+	Config    aws.Config
+	RoleARN   string
+	// roleCredentials holds the assume-role credentials of RoleARN per region.
+	roleCredentials *regionCredentials
+}
+
+// newRoleScanTarget returns a target scanning accountID by assuming roleARN with the cfg credentials.
+func newRoleScanTarget(accountID string, cfg aws.Config, roleARN string) scanTarget {
+	return scanTarget{AccountID: accountID, Config: cfg, RoleARN: roleARN, roleCredentials: &regionCredentials{}}
+}
+
+// ConfigForRegion returns the configuration to use for calls to region. Assume-role credentials are
+// obtained from the STS endpoint of that region instead of the global endpoint, for latency and so
+// that an STS outage in one region does not affect the others.
+func (t scanTarget) ConfigForRegion(region string) aws.Config {
+	regionCfg := t.Config.Copy()
+	regionCfg.Region = region
+
+	if t.RoleARN != "" {
+		regionCfg.Credentials = t.roleCredentials.get(regionCfg, t.RoleARN)
+	}
+
+	return regionCfg
+}
+
+// regionCredentials caches one assume-role credentials provider per region, so that the role of an account
+// is assumed once per region for the whole scan. A nil regionCredentials caches nothing.
+type regionCredentials struct {
+	mu        sync.Mutex
+	providers map[string]*aws.CredentialsCache
+}
+
+// get returns the credentials of roleARN assumed through the STS endpoint of the cfg region.
+func (c *regionCredentials) get(cfg aws.Config, roleARN string) *aws.CredentialsCache {
+	if c == nil {
+		return newAssumeRoleCredentials(cfg, roleARN)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if provider, ok := c.providers[cfg.Region]; ok {
+		return provider
+	}
+
+	if c.providers == nil {
+		c.providers = map[string]*aws.CredentialsCache{}
+	}
+
+	provider := newAssumeRoleCredentials(cfg, roleARN)
+	c.providers[cfg.Region] = provider
+
+	return provider
+}
+
+// newAssumeRoleCredentials returns the credentials of roleARN, assumed with the cfg credentials through
+// the STS endpoint of the cfg region.
+func newAssumeRoleCredentials(cfg aws.Config, roleARN string) *aws.CredentialsCache {
+	stsClient := sts.NewFromConfig(cfg, func(o *sts.Options) {
+		o.Region = cfg.Region
+	})
+
+	return aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, roleARN))
+}
+
+// isActiveAccount reports whether the organization member account is active; suspended and closing accounts are skipped.
+func isActiveAccount(account orgTypes.Account) bool {
+	return account.State == orgTypes.AccountStateActive
+}
+
+// listActiveOrgAccounts returns the active member accounts of the organization.
+func listActiveOrgAccounts(ctx context.Context, client organizationsAPI) ([]orgTypes.Account, error) {
+	var accounts []orgTypes.Account
+
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+
+		for _, account := range page.Accounts {
+			if !isActiveAccount(account) {
+				log.Printf("Skipping account %s (%s): %s", aws.ToString(account.Id), aws.ToString(account.Name), account.State)
+				continue
+			}
+
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts, nil
+}
+
+// orgScanTargets returns a scan target for every active account of the organization. The
+// caller's own account is scanned with cfg as is; every other account by assuming roleName.
+func orgScanTargets(ctx context.Context, cfg aws.Config, client organizationsAPI, callerARN string, roleName string) ([]scanTarget, error) {
+	caller, err := arn.Parse(callerARN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse caller ARN: %w", err)
+	}
+
+	accounts, err := listActiveOrgAccounts(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]scanTarget, 0, len(accounts))
+
+	for _, account := range accounts {
+		accountID := aws.ToString(account.Id)
+
+		if accountID == caller.AccountID {
+			targets = append(targets, scanTarget{AccountID: accountID, Config: cfg})
+			continue
+		}
+
+		roleARN := fmt.Sprintf("arn:%s:iam::%s:role/%s", caller.Partition, accountID, roleName)
+
+		targets = append(targets, newRoleScanTarget(accountID, cfg, roleARN))
+	}
+
+	return targets, nil
+}
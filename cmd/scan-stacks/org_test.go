@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+const testMemberAccount = "222222222222"
+
+// fakeOrganizations returns each page of accounts in turn.
+type fakeOrganizations struct {
+	pages [][]orgTypes.Account
+	calls int
+}
+
+func (f *fakeOrganizations) ListAccounts(_ context.Context, _ *organizations.ListAccountsInput, _ ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	output := &organizations.ListAccountsOutput{Accounts: f.pages[f.calls]}
+
+	f.calls++
+	if f.calls < len(f.pages) {
+		output.NextToken = aws.String("next")
+	}
+
+	return output, nil
+}
+
+func TestOrgScanTargetsSkipsSuspendedAccounts(t *testing.T) {
+	logs := captureLog(t)
+
+	client := &fakeOrganizations{pages: [][]orgTypes.Account{
+		{{Id: aws.String(testMemberAccount), Name: aws.String("workloads"), State: orgTypes.AccountStateActive}},
+		{{Id: aws.String("333333333333"), Name: aws.String("retired"), State: orgTypes.AccountStateSuspended}},
+	}}
+
+	targets, err := orgScanTargets(context.Background(), aws.Config{}, client,
+		"arn:aws:sts::"+testAccount+":assumed-role/Admin/session", DefaultOrgRole)
+	if err != nil {
+		t.Fatalf("orgScanTargets error: %v", err)
+	}
+
+	if len(targets) != 1 || targets[0].AccountID != testMemberAccount {
+		t.Fatalf("targets = %+v, want only %s", targets, testMemberAccount)
+	}
+
+	if want := "arn:aws:iam::" + testMemberAccount + ":role/" + DefaultOrgRole; targets[0].RoleARN != want {
+		t.Errorf("RoleARN = %q, want %q", targets[0].RoleARN, want)
+	}
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)}},
+	}
+	s := newTestScanner(t, account, false, "-org", "-output", "json")
+
+	for _, target := range targets {
+		if err := s.scanAccount(context.Background(), target, testDefaultRegion); err != nil {
+			t.Fatalf("scanAccount error: %v", err)
+		}
+	}
+
+	var scanned []string
+	for _, region := range s.report.Regions {
+		scanned = append(scanned, region.Account)
+	}
+
+	if !slices.Equal(scanned, []string{testMemberAccount}) {
+		t.Errorf("scanned accounts = %v, want [%s]", scanned, testMemberAccount)
+	}
+
+	if want := "Skipping account 333333333333 (retired): SUSPENDED"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
+
+func TestOrgScanTargetsScansCallerAccountWithoutRole(t *testing.T) {
+	captureLog(t)
+
+	client := &fakeOrganizations{pages: [][]orgTypes.Account{{
+		{Id: aws.String(testAccount), State: orgTypes.AccountStateActive},
+		{Id: aws.String(testMemberAccount), State: orgTypes.AccountStateActive},
+	}}}
+
+	targets, err := orgScanTargets(context.Background(), aws.Config{}, client, "arn:aws:iam::"+testAccount+":user/ops", "Auditor")
+	if err != nil {
+		t.Fatalf("orgScanTargets error: %v", err)
+	}
+
+	if len(targets) != 2 || targets[0].RoleARN != "" || targets[1].RoleARN != "arn:aws:iam::"+testMemberAccount+":role/Auditor" {
+		t.Errorf("targets = %+v, want the caller without a role and the member with Auditor", targets)
+	}
+}
+
+// assumeRoleHTTPClient answers AssumeRole requests and records the host of each request.
+type assumeRoleHTTPClient struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (c *assumeRoleHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.hosts = append(c.hosts, req.URL.Host)
+	c.mu.Unlock()
+
+	body := `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>ASIAMEMBER</AccessKeyId>` +
+		`<SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+		`<Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestConfigForRegionAssumesRoleThroughRegionalSTS(t *testing.T) {
+	client := &assumeRoleHTTPClient{}
+	cfg := aws.Config{
+		Region:      testDefaultRegion,
+		Credentials: credentials.NewStaticCredentialsProvider("AKIACALLER", "secret", ""),
+		HTTPClient:  client,
+	}
+
+	target := newRoleScanTarget(testMemberAccount, cfg, "arn:aws:iam::"+testMemberAccount+":role/"+DefaultOrgRole)
+
+	// The role is assumed once per region, however often the region's configuration is requested.
+	for _, region := range []string{"eu-west-1", "eu-west-1", "ap-southeast-2"} {
+		creds, err := target.ConfigForRegion(region).Credentials.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Retrieve error: %v", region, err)
+		}
+
+		if creds.AccessKeyID != "ASIAMEMBER" {
+			t.Errorf("%s: access key = %q, want the assumed role's", region, creds.AccessKeyID)
+		}
+	}
+
+	if want := []string{"sts.eu-west-1.amazonaws.com", "sts.ap-southeast-2.amazonaws.com"}; !slices.Equal(client.hosts, want) {
+		t.Errorf("STS hosts = %v, want %v", client.hosts, want)
+	}
+
+	if target.ConfigForRegion("eu-west-1").Credentials != target.ConfigForRegion("eu-west-1").Credentials {
+		t.Error("ConfigForRegion returned a new credentials provider for a region it already has one for")
+	}
+}
+
+func TestScanAccountsSkipsFailingMemberAccount(t *testing.T) {
+	logs := captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "eu-west-1"},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)}},
+	}
+	s := newTestScanner(t, account, false, "-org", "-output", "json", "-concurrency", "1")
+
+	// The role of 333333333333 cannot be assumed, and an SCP denies CloudFormation in eu-west-1.
+	s.listRegions = func(ctx context.Context, cfg aws.Config, allRegions bool) (*[]ec2Types.Region, error) {
+		if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+			return nil, fmt.Errorf("failed to describe regions: %w", err)
+		}
+
+		return account.listRegions(ctx, cfg, allRegions)
+	}
+	s.listStacks = func(ctx context.Context, cfg aws.Config) (*[]cfTypes.StackSummary, error) {
+		if cfg.Region == "eu-west-1" {
+			return nil, fmt.Errorf("failed to list stacks: %w", errors.New("AccessDenied: explicit deny in a service control policy"))
+		}
+
+		return account.listStacks(ctx, cfg)
+	}
+
+	allowed := credentials.NewStaticCredentialsProvider("AKIAMEMBER", "secret", "")
+	denied := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("AccessDenied: not authorized to perform sts:AssumeRole")
+	})
+
+	s.scanAccounts(context.Background(), []scanTarget{
+		{AccountID: "333333333333", Config: aws.Config{Credentials: denied}},
+		{AccountID: testMemberAccount, Config: aws.Config{Credentials: allowed}},
+		{AccountID: testAccount, Config: aws.Config{Credentials: allowed}},
+	}, testDefaultRegion)
+
+	var scanned []string
+	for _, region := range s.report.Regions {
+		if len(region.Stacks) > 0 {
+			scanned = append(scanned, region.Account)
+		}
+	}
+
+	if !slices.Equal(scanned, []string{testMemberAccount, testAccount}) {
+		t.Errorf("accounts with stacks = %v, want [%s %s]", scanned, testMemberAccount, testAccount)
+	}
+
+	if want := "Error scanning account 333333333333"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// cloudFormationDescribeStack retrieves a single CloudFormation stack by name or id.
+func cloudFormationDescribeStack(ctx context.Context, cfg aws.Config, stackName string) (*cfTypes.Stack, error) {
+	cfClient := cloudformation.NewFromConfig(cfg)
+
+	input := cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	}
+
+	output, err := cfClient.DescribeStacks(ctx, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe stack: %w", err)
+	}
+
+	if len(output.Stacks) == 0 {
+		return nil, fmt.Errorf("stack '%s' not found", stackName)
+	}
+
+	return &output.Stacks[0], nil
+}
+
+// sanitizeEnvName turns an output key into a valid shell variable name by replacing
+// every character other than letters, digits and underscores with an underscore.
+func sanitizeEnvName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// shellQuote single-quotes value so the shell takes it literally.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// writeOutputsAsEnv writes the stack outputs as `export KEY=VALUE` lines.
+func writeOutputsAsEnv(w io.Writer, outputs []cfTypes.Output) error {
+	for _, output := range outputs {
+		if output.OutputKey == nil {
+			continue
+		}
+
+		line := fmt.Sprintf("export %s=%s\n", sanitizeEnvName(*output.OutputKey), shellQuote(aws.ToString(output.OutputValue)))
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestWriteOutputsAsEnv(t *testing.T) {
+	outputs := []cfTypes.Output{
+		{OutputKey: aws.String("BucketName"), OutputValue: aws.String("app-logs")},
+		{OutputKey: aws.String("api-url.v2"), OutputValue: aws.String("https://example.com/?a=1&b=2")},
+		{OutputKey: aws.String("1stSubnet"), OutputValue: aws.String("subnet-0abc")},
+		{OutputKey: aws.String("Greeting"), OutputValue: aws.String("it's here")},
+		{OutputKey: aws.String("Empty")},
+		{OutputValue: aws.String("no key")},
+	}
+
+	var buf bytes.Buffer
+	if err := writeOutputsAsEnv(&buf, outputs); err != nil {
+		t.Fatalf("writeOutputsAsEnv error: %v", err)
+	}
+
+	want := `export BucketName='app-logs'
+export api_url_v2='https://example.com/?a=1&b=2'
+export _1stSubnet='subnet-0abc'
+export Greeting='it'\''s here'
+export Empty=''
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeOutputsAsEnv() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSanitizeEnvName(t *testing.T) {
+	tests := map[string]string{
+		"Name":     "Name",
+		"my-key":   "my_key",
+		"a.b/c d":  "a_b_c_d",
+		"9lives":   "_9lives",
+		"":         "_",
+		"ünïcode":  "_n_code",
+		"UPPER_OK": "UPPER_OK",
+	}
+
+	for key, want := range tests {
+		if got := sanitizeEnvName(key); got != want {
+			t.Errorf("sanitizeEnvName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// awsResourceTypePrefix is the namespace of the resource types provided by AWS.
+const awsResourceTypePrefix = "AWS::"
+
+// normalizedResourceID is the canonical form of a physical resource id, so the same resource can be
+// recognized whether it is referred to by ARN, id or name.
+type normalizedResourceID struct {
+	// Service is the AWS service (IAM prefix) of the resource, e.g. ec2.
+	Service string `json:"service"`
+	// Type is the resource type within the service as used in ARNs, e.g. security-group.
+	Type string `json:"type"`
+	// ID is the id or name of the resource, without the ARN parts.
+	ID string `json:"id"`
+}
+
+// String returns the normalized id as service:type:id, for use as a map key.
+func (n normalizedResourceID) String() string {
+	return n.Service + ":" + n.Type + ":" + n.ID
+}
+
+// arnResourceType returns the ARN style type of a CloudFormation resource type, e.g.
+// AWS::EC2::SecurityGroup -> security-group and AWS::EC2::VPCEndpoint -> vpc-endpoint.
+func arnResourceType(resourceType string) string {
+	parts := strings.Split(resourceType, "::")
+	name := []rune(parts[len(parts)-1])
+
+	var b strings.Builder
+
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			previousLower := unicode.IsLower(name[i-1])
+			nextLower := i+1 < len(name) && unicode.IsLower(name[i+1])
+
+			if previousLower || (unicode.IsUpper(name[i-1]) && nextLower) {
+				b.WriteRune('-')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// normalizePhysicalID returns the normalized id of the resource of resourceType with physicalID. ARNs
+// are split into service, type and id; plain ids and names take the service and type from the resource
+// type. ok is false when neither is derivable, e.g. for the arbitrary ids of custom resources.
+func normalizePhysicalID(resourceType string, physicalID string) (normalizedResourceID, bool) {
+	if physicalID == "" {
+		return normalizedResourceID{}, false
+	}
+
+	if parsed, err := arn.Parse(physicalID); err == nil {
+		normalized := normalizedResourceID{Service: parsed.Service, ID: parsed.Resource}
+
+		// The resource part is type/id, type:id or, e.g. for S3 buckets and SQS queues, just the id.
+		if separator := strings.IndexAny(parsed.Resource, "/:"); separator > 0 {
+			normalized.Type = parsed.Resource[:separator]
+			normalized.ID = parsed.Resource[separator+1:]
+		} else if strings.HasPrefix(resourceType, awsResourceTypePrefix) {
+			normalized.Type = arnResourceType(resourceType)
+		}
+
+		return normalized, true
+	}
+
+	if !strings.HasPrefix(resourceType, awsResourceTypePrefix) {
+		return normalizedResourceID{}, false
+	}
+
+	return normalizedResourceID{
+		Service: resourceTypeService(resourceType).Service,
+		Type:    arnResourceType(resourceType),
+		ID:      physicalID,
+	}, true
+}
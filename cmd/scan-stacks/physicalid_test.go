@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestNormalizePhysicalID(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		physicalID   string
+		want         normalizedResourceID
+	}{
+		{
+			"arn with type and id",
+			"AWS::EC2::SecurityGroup",
+			"arn:aws:ec2:us-west-2:" + testAccount + ":security-group/sg-0123456789abcdef0",
+			normalizedResourceID{Service: "ec2", Type: "security-group", ID: "sg-0123456789abcdef0"},
+		},
+		{
+			"arn with colon separated type",
+			"AWS::Lambda::Function",
+			"arn:aws:lambda:us-west-2:" + testAccount + ":function:api-handler",
+			normalizedResourceID{Service: "lambda", Type: "function", ID: "api-handler"},
+		},
+		{
+			"arn with only the id",
+			"AWS::S3::Bucket",
+			"arn:aws:s3:::web-assets",
+			normalizedResourceID{Service: "s3", Type: "bucket", ID: "web-assets"},
+		},
+		{
+			"arn without a resource type",
+			"",
+			"arn:aws:iam::" + testAccount + ":role/web-role",
+			normalizedResourceID{Service: "iam", Type: "role", ID: "web-role"},
+		},
+		{
+			"bare id",
+			"AWS::EC2::Instance",
+			"i-0123456789abcdef0",
+			normalizedResourceID{Service: "ec2", Type: "instance", ID: "i-0123456789abcdef0"},
+		},
+		{
+			"bare id of an acronym type",
+			"AWS::EC2::VPCEndpoint",
+			"vpce-0123456789abcdef0",
+			normalizedResourceID{Service: "ec2", Type: "vpc-endpoint", ID: "vpce-0123456789abcdef0"},
+		},
+		{
+			"name",
+			"AWS::IAM::Role",
+			"web-role",
+			normalizedResourceID{Service: "iam", Type: "role", ID: "web-role"},
+		},
+		{
+			"name of an overridden namespace",
+			"AWS::StepFunctions::StateMachine",
+			"orders",
+			normalizedResourceID{Service: "states", Type: "state-machine", ID: "orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := normalizePhysicalID(tt.resourceType, tt.physicalID)
+			if !ok || got != tt.want {
+				t.Errorf("normalizePhysicalID(%q, %q) = %+v, %t, want %+v", tt.resourceType, tt.physicalID, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePhysicalIDMatchesNameAndARN(t *testing.T) {
+	name, _ := normalizePhysicalID("AWS::IAM::Role", "web-role")
+	fromARN, _ := normalizePhysicalID("", "arn:aws:iam::"+testAccount+":role/web-role")
+
+	if name.String() != "iam:role:web-role" || fromARN.String() != name.String() {
+		t.Errorf("normalized name %q and ARN %q, want both iam:role:web-role", name, fromARN)
+	}
+}
+
+func TestNormalizePhysicalIDNotDerivable(t *testing.T) {
+	for _, tt := range []struct{ resourceType, physicalID string }{
+		{"AWS::S3::Bucket", ""},
+		{"Custom::CertificateValidator", "a1b2c3"},
+		{"", "web-role"},
+	} {
+		if got, ok := normalizePhysicalID(tt.resourceType, tt.physicalID); ok {
+			t.Errorf("normalizePhysicalID(%q, %q) = %+v, want not derivable", tt.resourceType, tt.physicalID, got)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// DefaultConcurrency is the default number of regions and stacks listed at the same time.
+const DefaultConcurrency = 4
+
+// regionsFunc lists the regions enabled for the account of cfg, or all regions with allRegions.
+type regionsFunc func(ctx context.Context, cfg aws.Config, allRegions bool) (*[]ec2Types.Region, error)
+
+// zonesFunc lists the availability zones of the region cfg points to.
+type zonesFunc func(ctx context.Context, cfg aws.Config) ([]ec2Types.AvailabilityZone, error)
+
+// stacksFunc lists the stacks of the region cfg points to.
+type stacksFunc func(ctx context.Context, cfg aws.Config) (*[]cfTypes.StackSummary, error)
+
+// stackTagsFunc returns the tags of every stack in the region cfg points to, by stack id.
+type stackTagsFunc func(ctx context.Context, cfg aws.Config) (map[string]map[string]string, error)
+
+// regionStackEventsFunc returns the stackEventsFunc of the region cfg points to.
+type regionStackEventsFunc func(cfg aws.Config) stackEventsFunc
+
+// stackResourcesFunc lists the resources of a stack page by page.
+type stackResourcesFunc func(ctx context.Context, cfg aws.Config, stackID string, throttle *adaptiveThrottle, onPage stackResourcePageFunc) (*[]cfTypes.StackResourceSummary, error)
+
+// regionScan is one region moving through the scan pipeline. The listing fields are set by the
+// region stage; entries and pending belong to the consumer.
+type regionScan struct {
+	index      int
+	cfg        aws.Config
+	account    string
+	name       string
+	zones      []ec2Types.AvailabilityZone
+	zonesError error
+	zoneNames  []string
+	stacks     []cfTypes.StackSummary
+	listError  error
+	rootCauses []stackRootCause
+	// credsSource is the credentials provider name, set with -show-creds-source.
+	credsSource string
+	// tags are the stack tags by stack id, set with -stackset-origin, -group-by-tag and -policy.
+	tags map[string]map[string]string
+	// tagsError is the error listing the stack tags, which leaves the region unreported.
+	tagsError error
+	// throttle paces the resource listing of the region with -max-rps; every account and region has its own
+	// CloudFormation request limits, so it is not shared with other regions.
+	throttle *adaptiveThrottle
+	// hidden leaves a region without stacks out of the text output with -only-regions-with-stacks.
+	hidden bool
+
+	entries []stackReport
+	pending int
+}
+
+// stackJob asks the stack stage to list the resources of one stack of a region.
+type stackJob struct {
+	region *regionScan
+	index  int
+}
+
+// scanResultKind tells the consumer what a scanResult carries.
+type scanResultKind int
+
+const (
+	// resultRegionListed carries a region whose stacks have been listed.
+	resultRegionListed scanResultKind = iota
+	// resultResource carries one resource of a stack.
+	resultResource
+	// resultStackDone marks that all resources of a stack have been sent.
+	resultStackDone
+)
+
+// scanResult is a message from the region and stack stages to the consumer.
+type scanResult struct {
+	kind     scanResultKind
+	region   *regionScan
+	index    int
+	resource cfTypes.StackResourceSummary
+	// timeline is the status history of the resource, set with -resource-timeline.
+	timeline *resourceTimeline
+}
+
+// scanRegions runs the scan of the given regions of an account as a pipeline: regions are listed
+// for stacks, stacks for resources, and a single consumer feeds everything to the reports. The
+// stages are connected by channels buffered to the concurrency, so a large account blocks the
+// listing instead of piling up results in memory.
+func (s *scanner) scanRegions(ctx context.Context, target scanTarget, regionNames []string) {
+	concurrency := s.opts.concurrency
+
+	regionJobs := make(chan *regionScan, concurrency)
+	stackJobs := make(chan stackJob, concurrency)
+	results := make(chan scanResult, concurrency)
+
+	go func() {
+		defer close(regionJobs)
+
+		for i, regionName := range regionNames {
+			regionJobs <- &regionScan{index: i, account: target.AccountID, name: regionName}
+		}
+	}()
+
+	var regionWorkers, stackWorkers sync.WaitGroup
+
+	for range concurrency {
+		regionWorkers.Add(1)
+
+		go func() {
+			defer regionWorkers.Done()
+
+			for region := range regionJobs {
+				region.cfg = target.ConfigForRegion(region.name)
+				region.throttle = s.newThrottle()
+				s.listRegion(ctx, region, stackJobs, results)
+			}
+		}()
+
+		stackWorkers.Add(1)
+
+		go func() {
+			defer stackWorkers.Done()
+
+			for job := range stackJobs {
+				s.listStack(ctx, job, results)
+			}
+		}()
+	}
+
+	go func() {
+		regionWorkers.Wait()
+		close(stackJobs)
+		stackWorkers.Wait()
+		close(results)
+	}()
+
+	done := make([]*regionReport, len(regionNames))
+
+	for result := range results {
+		if region := s.consume(result); region != nil {
+			done[result.region.index] = region
+		}
+	}
+
+	if s.report != nil {
+		for _, region := range done {
+			if region != nil {
+				s.report.Regions = append(s.report.Regions, *region)
+			}
+		}
+	}
+}
+
+// listRegion lists the stacks of a region, sends the region to the consumer and queues its stacks.
+func (s *scanner) listRegion(ctx context.Context, region *regionScan, stackJobs chan<- stackJob, results chan<- scanResult) {
+	if s.opts.showCredsSource {
+		source, err := credentialsSource(ctx, region.cfg.Credentials)
+		if err != nil {
+			log.Printf("Error resolving credentials source: %v", err)
+		}
+
+		region.credsSource = source
+	}
+
+	if s.opts.azs {
+		region.zones, region.zonesError = s.listZones(ctx, region.cfg)
+	}
+
+	stacks, err := s.listStacks(ctx, region.cfg)
+	if err != nil {
+		region.listError = err
+		results <- scanResult{kind: resultRegionListed, region: region}
+
+		return
+	}
+
+	region.stacks = *stacks
+
+	if s.opts.inProgressCount {
+		// Only the stack statuses are needed, so skip listing the resources.
+		results <- scanResult{kind: resultRegionListed, region: region}
+
+		return
+	}
+
+	// -stackset-origin, -group-by-tag and -policy would be wrong rather than incomplete without the tags,
+	// so a region whose tags cannot be read is not reported.
+	if s.opts.needsStackTags() && len(region.stacks) > 0 {
+		tags, terr := s.listStackTags(ctx, region.cfg)
+		if terr != nil {
+			region.tagsError = terr
+			results <- scanResult{kind: resultRegionListed, region: region}
+
+			return
+		}
+
+		region.tags = tags
+	}
+
+	if s.opts.rootCause {
+		region.rootCauses = findStackRootCauses(ctx, s.stackEvents(region.cfg), region.name, region.stacks,
+			s.opts.maxDepth)
+	}
+
+	// The region must reach the consumer before any of its resources.
+	results <- scanResult{kind: resultRegionListed, region: region}
+
+	for i := range region.stacks {
+		stackJobs <- stackJob{region: region, index: i}
+	}
+}
+
+// listStack sends the resources of one stack to the consumer, followed by a stack done marker.
+func (s *scanner) listStack(ctx context.Context, job stackJob, results chan<- scanResult) {
+	stack := job.region.stacks[job.index]
+
+	// The timelines are built before the resources are listed, so each resource goes out with its timeline.
+	var timelines map[string]*resourceTimeline
+
+	if s.opts.resourceTimeline {
+		events, eerr := s.stackEvents(job.region.cfg)(ctx, aws.ToString(stack.StackId))
+		if eerr != nil {
+			log.Printf("Error calling cloudFormationStackEventsFunc: %v", eerr)
+		} else {
+			timelines = buildResourceTimelines(events)
+		}
+	}
+
+	_, err := s.listStackResources(ctx, job.region.cfg, aws.ToString(stack.StackId), job.region.throttle, func(page []cfTypes.StackResourceSummary) error {
+		for _, resource := range page {
+			results <- scanResult{
+				kind:     resultResource,
+				region:   job.region,
+				index:    job.index,
+				resource: resource,
+				timeline: timelines[aws.ToString(resource.LogicalResourceId)],
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error calling cloudFormationListStackResources: %v", err)
+	}
+
+	results <- scanResult{kind: resultStackDone, region: job.region, index: job.index}
+}
+
+// consume feeds one pipeline result to the enabled reports. It returns the report of a region once
+// all of its stacks are done.
+func (s *scanner) consume(result scanResult) *regionReport {
+	region := result.region
+
+	switch result.kind {
+	case resultRegionListed:
+		return s.startRegion(region)
+	case resultResource:
+		s.addStackResource(region.account, region.name, region.stacks[result.index], result.resource, result.timeline,
+			&region.entries[result.index])
+	case resultStackDone:
+		s.finishStack(region, result.index)
+
+		region.pending--
+		if region.pending == 0 {
+			return s.finishRegion(region)
+		}
+	}
+
+	return nil
+}
+
+// startRegion feeds a listed region to the enabled reports and prepares the report entries of its stacks.
+func (s *scanner) startRegion(region *regionScan) *regionReport {
+	region.hidden = s.opts.onlyRegionsWithStacks && region.listError == nil && len(region.stacks) == 0
+	if region.hidden {
+		s.emptyRegions = append(s.emptyRegions, regionCoverageKey(region.account, region.name))
+	} else {
+		log.Printf("- Region: %s\n", region.name)
+	}
+
+	s.emit(scanEvent{Type: EventRegionStarted, Account: region.account, Region: region.name})
+
+	if region.credsSource != "" && !region.hidden {
+		log.Printf("  - Credentials Source: %s (account %s)", region.credsSource, region.account)
+	}
+
+	if region.zonesError != nil {
+		log.Printf("Error calling getAvailabilityZones: %v", region.zonesError)
+	} else if s.opts.azs {
+		region.zoneNames = availabilityZoneNames(region.zones)
+	}
+
+	if region.listError != nil {
+		log.Printf("Error calling cloudFormationListStacks: %v", region.listError)
+		s.emit(scanEvent{Type: EventRegionDone, Account: region.account, Region: region.name})
+
+		return nil
+	}
+
+	if region.tagsError != nil {
+		log.Printf("Error calling cloudFormationStackTags: %v", region.tagsError)
+		s.emit(scanEvent{Type: EventRegionDone, Account: region.account, Region: region.name})
+
+		return nil
+	}
+
+	if s.opts.inProgressCount {
+		s.inProgress = append(s.inProgress, findInProgressStacks(region.account, region.name, region.stacks)...)
+		s.emit(scanEvent{Type: EventRegionDone, Account: region.account, Region: region.name, StackCount: len(region.stacks)})
+
+		return nil
+	}
+
+	if s.opts.driftStale > 0 {
+		s.staleDrift = append(s.staleDrift, findStaleDriftStacks(region.name, region.stacks, s.opts.driftStale, s.scanTime)...)
+	}
+
+	if s.opts.eventBusName != "" || s.opts.output == OutputOCSF {
+		s.findings = append(s.findings, findStackFindings(region.account, region.name, region.stacks)...)
+	}
+
+	s.rootCauses = append(s.rootCauses, region.rootCauses...)
+
+	region.entries = make([]stackReport, 0, len(region.stacks))
+
+	for _, stack := range region.stacks {
+		s.emit(scanEvent{Type: EventStackFound, Account: region.account, Region: region.name, Stack: &eventStack{
+			StackID:     aws.ToString(stack.StackId),
+			StackName:   aws.ToString(stack.StackName),
+			StackStatus: string(stack.StackStatus),
+		}})
+
+		entry := newStackReport(stack)
+		if s.opts.statusCounts {
+			entry.ResourceStatusCounts = map[string]int{}
+		}
+
+		tags := region.tags[aws.ToString(stack.StackId)]
+		if s.opts.stackSetOrigin {
+			entry.StackSetName = stackSetOrigin(tags)
+		}
+
+		if s.opts.groupByTag != "" || s.opts.policyPath != "" {
+			entry.Tags = tags
+		}
+
+		if s.opts.groupByTag != "" {
+			s.tagGroups = append(s.tagGroups, tagGroupStack{
+				Group:       tagGroup(entry.Tags, s.opts.groupByTag),
+				Account:     region.account,
+				Region:      region.name,
+				StackName:   aws.ToString(stack.StackName),
+				StackStatus: string(stack.StackStatus),
+			})
+		}
+
+		if s.verbose && s.textOutput {
+			// Stacks of several regions are scanned at once, so every stack and resource names its region.
+			s.format.logStack(region.name, entry)
+		}
+
+		region.entries = append(region.entries, entry)
+	}
+
+	region.pending = len(region.stacks)
+	if region.pending == 0 {
+		return s.finishRegion(region)
+	}
+
+	return nil
+}
+
+// finishStack feeds a stack whose resources have all been consumed to the reports that need the complete stack.
+func (s *scanner) finishStack(region *regionScan, index int) {
+	entry := &region.entries[index]
+
+	if s.opts.statusCounts && s.textOutput {
+		s.statusCounts = append(s.statusCounts, stackStatusCounts{
+			Account:   region.account,
+			Region:    region.name,
+			StackName: aws.ToString(entry.StackName),
+			Counts:    entry.ResourceStatusCounts,
+		})
+	}
+}
+
+// finishRegion returns the report of a region whose stacks are all done.
+func (s *scanner) finishRegion(region *regionScan) *regionReport {
+	report := &regionReport{Account: region.account, Region: region.name, AvailabilityZones: region.zoneNames, Stacks: region.entries}
+
+	s.emit(scanEvent{Type: EventRegionDone, Account: region.account, Region: region.name, StackCount: len(region.stacks)})
+
+	return report
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// syntheticAccount generates the stacks and resources of a large account page by page, without holding them,
+// and records the peak heap while the resources are listed.
+type syntheticAccount struct {
+	regions           []string
+	stacksPerRegion   int
+	resourcesPerStack int
+	pageSize          int
+
+	mu       sync.Mutex
+	pages    int
+	peakHeap uint64
+}
+
+func (a *syntheticAccount) listRegions(_ context.Context, _ aws.Config, _ bool) (*[]ec2Types.Region, error) {
+	regions := make([]ec2Types.Region, 0, len(a.regions))
+	for _, name := range a.regions {
+		regions = append(regions, ec2Types.Region{RegionName: aws.String(name)})
+	}
+
+	return &regions, nil
+}
+
+func (a *syntheticAccount) listStacks(_ context.Context, cfg aws.Config) (*[]cfTypes.StackSummary, error) {
+	stacks := make([]cfTypes.StackSummary, 0, a.stacksPerRegion)
+	for i := range a.stacksPerRegion {
+		stacks = append(stacks, testStack(cfg.Region, fmt.Sprintf("stack-%d", i), cfTypes.StackStatusCreateComplete))
+	}
+
+	return &stacks, nil
+}
+
+func (a *syntheticAccount) listStackTags(_ context.Context, _ aws.Config) (map[string]map[string]string, error) {
+	return map[string]map[string]string{}, nil
+}
+
+func (a *syntheticAccount) listStackResources(_ context.Context, _ aws.Config, _ string, _ *adaptiveThrottle, onPage stackResourcePageFunc) (*[]cfTypes.StackResourceSummary, error) {
+	for start := 0; start < a.resourcesPerStack; start += a.pageSize {
+		page := make([]cfTypes.StackResourceSummary, 0, a.pageSize)
+		for i := start; i < min(start+a.pageSize, a.resourcesPerStack); i++ {
+			page = append(page, testResource(fmt.Sprintf("Queue%d", i), "AWS::SQS::Queue", fmt.Sprintf("queue-%d", i)))
+		}
+
+		if err := onPage(page); err != nil {
+			return nil, err
+		}
+
+		a.samplePeakHeap()
+	}
+
+	return &[]cfTypes.StackResourceSummary{}, nil
+}
+
+// samplePeakHeap records the heap in use every few pages.
+func (a *syntheticAccount) samplePeakHeap() {
+	const samplePages = 10
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pages++
+	if a.pages%samplePages != 0 {
+		return
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	a.peakHeap = max(a.peakHeap, stats.HeapAlloc)
+}
+
+// install makes s list regions, stacks, tags and resources from the synthetic account.
+func (a *syntheticAccount) install(s *scanner) {
+	s.listRegions = a.listRegions
+	s.listStacks = a.listStacks
+	s.listStackTags = a.listStackTags
+	s.listStackResources = a.listStackResources
+}
+
+// scanSyntheticAccount scans account with a verbose text scan logging to io.Discard and returns the number of
+// resources scanned, from the status counts, and the growth of the heap over the scan.
+func scanSyntheticAccount(tb testing.TB, account *syntheticAccount) (int, uint64) {
+	tb.Helper()
+
+	captureLog(tb)
+	log.SetOutput(io.Discard)
+
+	s := newTestScanner(tb, &fakeAccount{}, true, "-status-counts")
+	account.install(s)
+
+	runtime.GC()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, account.regions[0]); err != nil {
+		tb.Fatalf("scanAccount error: %v", err)
+	}
+
+	total := 0
+	for _, stack := range s.statusCounts {
+		total += stack.Counts[string(cfTypes.ResourceStatusCreateComplete)]
+	}
+
+	if account.peakHeap < before.HeapAlloc {
+		return total, 0
+	}
+
+	return total, account.peakHeap - before.HeapAlloc
+}
+
+func TestScanAccountStreamsLargeAccount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("scans 200000 resources")
+	}
+
+	// Held until their stack is done, the resources of the stacks listed at once would take tens of MB.
+	const maxHeapGrowth = 16 << 20
+
+	account := &syntheticAccount{
+		regions:           []string{testDefaultRegion, "us-east-1"},
+		stacksPerRegion:   4,
+		resourcesPerStack: 25000,
+		pageSize:          100,
+	}
+
+	total, growth := scanSyntheticAccount(t, account)
+
+	if want := len(account.regions) * account.stacksPerRegion * account.resourcesPerStack; total != want {
+		t.Errorf("scanned %d resources, want %d", total, want)
+	}
+
+	if growth > maxHeapGrowth {
+		t.Errorf("heap grew by %d MB while scanning, want at most %d MB", growth>>20, maxHeapGrowth>>20)
+	}
+}
+
+func BenchmarkScanAccount(b *testing.B) {
+	account := &syntheticAccount{
+		regions:           []string{testDefaultRegion, "us-east-1", "eu-west-1", "ap-southeast-2"},
+		stacksPerRegion:   10,
+		resourcesPerStack: 1000,
+		pageSize:          100,
+	}
+
+	for range b.N {
+		total, growth := scanSyntheticAccount(b, account)
+		if want := len(account.regions) * account.stacksPerRegion * account.resourcesPerStack; total != want {
+			b.Fatalf("scanned %d resources, want %d", total, want)
+		}
+
+		b.ReportMetric(float64(growth), "peak-heap-B/op")
+	}
+}
+
+func TestTextOutputNamesRegionOfEveryStackAndResource(t *testing.T) {
+	logs := captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	queue := testStack("us-east-1", "queue", cfTypes.StackStatusCreateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "us-east-1"},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web}, "us-east-1": {queue}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId:   {testResource("Bucket", "AWS::S3::Bucket", "web-assets")},
+			*queue.StackId: {testResource("Queue", "AWS::SQS::Queue", "jobs")},
+		},
+	}
+
+	s := newTestScanner(t, account, true)
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	output := logs.String()
+
+	for _, lines := range [][2]string{
+		{"- Stack: us-west-2/web\n", "  - Stack Resource: us-west-2/web/Bucket\n     - Physical Resource Id: web-assets\n"},
+		{"- Stack: us-east-1/queue\n", "  - Stack Resource: us-east-1/queue/Queue\n     - Physical Resource Id: jobs\n"},
+	} {
+		stack, resource := strings.Index(output, lines[0]), strings.Index(output, lines[1])
+		if stack < 0 || resource < stack {
+			t.Errorf("output does not have %q followed by %q:\n%s", lines[0], lines[1], output)
+		}
+	}
+}
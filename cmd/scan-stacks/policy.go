@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	// IAMPolicyVersion is the current IAM policy language version.
+	IAMPolicyVersion = "2012-10-17"
+	// AllResources is the IAM resource matching everything, used for actions that cannot be scoped.
+	AllResources = "*"
+)
+
+// iamPolicy is an IAM policy document.
+type iamPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []iamStatement `json:"Statement"`
+}
+
+// iamStatement is a single statement of an IAM policy document.
+type iamStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+// actionResource returns the resource an IAM action can be scoped to with the given options,
+// or AllResources when it cannot be scoped.
+func actionResource(opts *options, action string) string {
+	switch action {
+	case "events:PutEvents":
+		if strings.HasPrefix(opts.eventBusName, "arn:") {
+			return opts.eventBusName
+		}
+
+		return "arn:*:events:*:*:event-bus/" + opts.eventBusName
+	case "sts:AssumeRole":
+		return "arn:*:iam::*:role/" + opts.orgRole
+	default:
+		return AllResources
+	}
+}
+
+// generatePolicy returns a least-privilege IAM policy allowing exactly the actions scan-stacks
+// invokes with the given options, with one statement per distinct resource.
+func generatePolicy(opts *options) iamPolicy {
+	var resources []string
+
+	actionsByResource := map[string][]string{}
+
+	for _, action := range apiOperations(opts) {
+		resource := actionResource(opts, action)
+		if _, ok := actionsByResource[resource]; !ok {
+			resources = append(resources, resource)
+		}
+
+		actionsByResource[resource] = append(actionsByResource[resource], action)
+	}
+
+	slices.Sort(resources)
+
+	policy := iamPolicy{Version: IAMPolicyVersion, Statement: make([]iamStatement, 0, len(resources))}
+
+	for _, resource := range resources {
+		policy.Statement = append(policy.Statement, iamStatement{
+			Effect:   "Allow",
+			Action:   actionsByResource[resource],
+			Resource: []string{resource},
+		})
+	}
+
+	return policy
+}
+
+// writePolicy writes the generated policy as indented JSON.
+func writePolicy(w io.Writer, opts *options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(generatePolicy(opts)); err != nil {
+		return fmt.Errorf("failed to write policy: %w", err)
+	}
+
+	return nil
+}
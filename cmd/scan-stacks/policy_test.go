@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// policyActions returns the actions the policy allows, by resource.
+func policyActions(t *testing.T, args ...string) map[string][]string {
+	t.Helper()
+
+	opts, err := parseOptions("scan-stacks", args)
+	if err != nil {
+		t.Fatalf("parseOptions(%v) error: %v", args, err)
+	}
+
+	var buf bytes.Buffer
+	if err := writePolicy(&buf, opts); err != nil {
+		t.Fatalf("writePolicy error: %v", err)
+	}
+
+	var policy iamPolicy
+	if err := json.Unmarshal(buf.Bytes(), &policy); err != nil {
+		t.Fatalf("decoding policy %s: %v", buf.String(), err)
+	}
+
+	if policy.Version != IAMPolicyVersion {
+		t.Errorf("Version = %q, want %q", policy.Version, IAMPolicyVersion)
+	}
+
+	actions := map[string][]string{}
+
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" || len(statement.Resource) != 1 {
+			t.Errorf("statement %+v, want one allowed resource", statement)
+		}
+
+		actions[statement.Resource[0]] = append(actions[statement.Resource[0]], statement.Action...)
+	}
+
+	return actions
+}
+
+func TestGeneratePolicyDefaultFeatures(t *testing.T) {
+	actions := policyActions(t, "-gen-policy")
+
+	if len(actions) != 1 {
+		t.Fatalf("policy resources = %v, want only %s", actions, AllResources)
+	}
+
+	for _, action := range []string{
+		"cloudformation:ListStacks", "cloudformation:ListStackResources", "ec2:DescribeRegions", "sts:GetCallerIdentity",
+	} {
+		if !slices.Contains(actions[AllResources], action) {
+			t.Errorf("default policy does not allow %s: %v", action, actions[AllResources])
+		}
+	}
+
+	for _, action := range []string{
+		"events:PutEvents", "organizations:ListAccounts", "sts:AssumeRole",
+		"ec2:DescribeAvailabilityZones", "cloudformation:DescribeStackEvents", "cloudformation:DescribeStacks",
+	} {
+		if slices.Contains(actions[AllResources], action) {
+			t.Errorf("default policy allows %s of a disabled feature", action)
+		}
+	}
+}
+
+func TestGeneratePolicyScopesEnabledFeatures(t *testing.T) {
+	actions := policyActions(t, "-gen-policy", "-eventbridge", "ops-bus", "-org", "-org-role", "Auditor", "-in-progress-count")
+
+	want := map[string][]string{
+		AllResources: {
+			"cloudformation:ListStacks", "ec2:DescribeRegions", "organizations:ListAccounts", "sts:GetCallerIdentity",
+		},
+		"arn:*:events:*:*:event-bus/ops-bus": {"events:PutEvents"},
+		"arn:*:iam::*:role/Auditor":          {"sts:AssumeRole"},
+	}
+
+	if len(actions) != len(want) {
+		t.Errorf("policy resources = %v, want %v", actions, want)
+	}
+
+	for resource, wantActions := range want {
+		if got := slices.Sorted(slices.Values(actions[resource])); !slices.Equal(got, wantActions) {
+			t.Errorf("actions on %s = %v, want %v", resource, got, wantActions)
+		}
+	}
+}
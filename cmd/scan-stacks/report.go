@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// scanReport is everything a scan collected, as rendered by the structured outputs.
+type scanReport struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Regions     []regionReport `json:"regions"`
+	// RegionsWithoutStacks are the scanned account/region pairs left out of Regions with -only-regions-with-stacks.
+	RegionsWithoutStacks []string `json:"regionsWithoutStacks,omitempty"`
+}
+
+// regionReport holds the stacks found in one region of one account.
+type regionReport struct {
+	Account           string        `json:"account"`
+	Region            string        `json:"region"`
+	AvailabilityZones []string      `json:"availabilityZones,omitempty"`
+	Stacks            []stackReport `json:"stacks"`
+}
+
+// stackReport is a stack and its resources. Optional fields are nil when CloudFormation did not return them.
+type stackReport struct {
+	StackID           *string    `json:"stackId"`
+	StackName         *string    `json:"stackName"`
+	StackStatus       string     `json:"stackStatus"`
+	StackStatusReason *string    `json:"stackStatusReason"`
+	ParentID          *string    `json:"parentId"`
+	RootID            *string    `json:"rootId"`
+	StackSetName      *string    `json:"stackSetName"`
+	CreationTime      *time.Time `json:"creationTime"`
+	LastUpdatedTime   *time.Time `json:"lastUpdatedTime"`
+	DeletionTime      *time.Time `json:"deletionTime"`
+	// Tags are the stack tags, set with -group-by-tag and -policy.
+	Tags map[string]string `json:"tags,omitempty"`
+	// ResourceStatusCounts is the number of resources in each status, set with -status-counts.
+	ResourceStatusCounts map[string]int   `json:"resourceStatusCounts,omitempty"`
+	Resources            []resourceReport `json:"resources"`
+}
+
+// resourceReport is a single stack resource.
+type resourceReport struct {
+	LogicalResourceID    *string    `json:"logicalResourceId"`
+	PhysicalResourceID   *string    `json:"physicalResourceId"`
+	ResourceType         *string    `json:"resourceType"`
+	ResourceStatus       string     `json:"resourceStatus"`
+	ResourceStatusReason *string    `json:"resourceStatusReason"`
+	LastUpdatedTimestamp *time.Time `json:"lastUpdatedTimestamp"`
+	// Timeline is the status history of the resource, set with -resource-timeline.
+	Timeline *resourceTimeline `json:"timeline,omitempty"`
+}
+
+// newStackReport converts a stack summary into a report entry without resources.
+func newStackReport(stack cfTypes.StackSummary) stackReport {
+	return stackReport{
+		StackID:           stack.StackId,
+		StackName:         stack.StackName,
+		StackStatus:       string(stack.StackStatus),
+		StackStatusReason: stack.StackStatusReason,
+		ParentID:          stack.ParentId,
+		RootID:            stack.RootId,
+		CreationTime:      stack.CreationTime,
+		LastUpdatedTime:   stack.LastUpdatedTime,
+		DeletionTime:      stack.DeletionTime,
+		Resources:         []resourceReport{},
+	}
+}
+
+// newResourceReport converts a stack resource summary into a report entry.
+func newResourceReport(resource cfTypes.StackResourceSummary) resourceReport {
+	return resourceReport{
+		LogicalResourceID:    resource.LogicalResourceId,
+		PhysicalResourceID:   resource.PhysicalResourceId,
+		ResourceType:         resource.ResourceType,
+		ResourceStatus:       string(resource.ResourceStatus),
+		ResourceStatusReason: resource.ResourceStatusReason,
+		LastUpdatedTimestamp: resource.LastUpdatedTimestamp,
+	}
+}
+
+// readScanReport reads a JSON report written by a prior scan with -output json.
+func readScanReport(path string) (*scanReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var report scanReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// Operators of the policy conditions.
+const (
+	PolicyOperatorEqual    = "=="
+	PolicyOperatorNotEqual = "!="
+	// PolicyOperatorMatch matches a path.Match pattern, e.g. type ~= "AWS::EC2::*".
+	PolicyOperatorMatch = "~="
+)
+
+// Keywords of the policy rules.
+const (
+	PolicyKeywordWhen    = "when"
+	PolicyKeywordRequire = "require"
+	PolicyKeywordAnd     = "and"
+)
+
+// PolicyStackTagPrefix prefixes the fields naming a tag of the resource's stack, e.g. stack.tag.env.
+const PolicyStackTagPrefix = "stack.tag."
+
+// policyConditionTokens is the number of tokens of a condition: field, operator and value.
+const policyConditionTokens = 3
+
+// policyFields are the resource fields a condition can test, besides the stack tags.
+var policyFields = map[string]func(policyResource) string{
+	"account":       func(r policyResource) string { return r.Account },
+	"region":        func(r policyResource) string { return r.Region },
+	"type":          func(r policyResource) string { return aws.ToString(r.Resource.ResourceType) },
+	"logical_id":    func(r policyResource) string { return aws.ToString(r.Resource.LogicalResourceId) },
+	"physical_id":   func(r policyResource) string { return aws.ToString(r.Resource.PhysicalResourceId) },
+	"status":        func(r policyResource) string { return string(r.Resource.ResourceStatus) },
+	"status_reason": func(r policyResource) string { return aws.ToString(r.Resource.ResourceStatusReason) },
+	"stack.name":    func(r policyResource) string { return aws.ToString(r.Stack.StackName) },
+	"stack.status":  func(r policyResource) string { return string(r.Stack.StackStatus) },
+}
+
+// policyResource is a resource as seen by the policy, with its stack and the stack tags.
+type policyResource struct {
+	Account   string
+	Region    string
+	Stack     cfTypes.StackSummary
+	StackTags map[string]string
+	Resource  cfTypes.StackResourceSummary
+}
+
+// policyCondition compares a resource field to a value.
+type policyCondition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// String returns the condition as written in the policy.
+func (c policyCondition) String() string {
+	return fmt.Sprintf("%s %s %s", c.Field, c.Operator, strconv.Quote(c.Value))
+}
+
+// fieldValue returns the value of the condition field for r.
+func (c policyCondition) fieldValue(r policyResource) string {
+	if tag, ok := strings.CutPrefix(c.Field, PolicyStackTagPrefix); ok {
+		return r.StackTags[tag]
+	}
+
+	return policyFields[c.Field](r)
+}
+
+// Holds reports whether the condition holds for r.
+func (c policyCondition) Holds(r policyResource) bool {
+	value := c.fieldValue(r)
+
+	switch c.Operator {
+	case PolicyOperatorEqual:
+		return value == c.Value
+	case PolicyOperatorNotEqual:
+		return value != c.Value
+	default:
+		// The pattern was validated when the policy was parsed.
+		matched, _ := path.Match(c.Value, value)
+
+		return matched
+	}
+}
+
+// policyRule requires resources matching all When conditions to satisfy all Require conditions.
+type policyRule struct {
+	Name    string
+	When    []policyCondition
+	Require []policyCondition
+}
+
+// resourcePolicy is a list of rules evaluated against every scanned resource.
+//
+// A policy file has one rule per line; blank lines and lines starting with # are ignored:
+//
+//	<name>: [when <condition> [and <condition>...]] require <condition> [and <condition>...]
+//
+// A condition is <field> <operator> <value>, where the operator is ==, != or ~= (path.Match pattern),
+// the value is a bare word or a quoted string, and the field is one of account, region, type, logical_id,
+// physical_id, status, status_reason, stack.name, stack.status or stack.tag.<key>. Only bare words are
+// keywords, so a quoted "and" is a value. E.g.:
+//
+//	ec2-in-prod: when type == "AWS::EC2::Instance" require stack.tag.env == "prod"
+type resourcePolicy struct {
+	Rules []policyRule
+}
+
+// policyViolation is a resource that fails a rule.
+type policyViolation struct {
+	Rule              string
+	Account           string
+	Region            string
+	StackName         string
+	LogicalResourceID string
+	ResourceType      string
+	// Condition is the first required condition that does not hold.
+	Condition string
+}
+
+// policyToken is a word, operator or quoted string of a policy line.
+type policyToken struct {
+	Text string
+	// Quoted is set for a quoted string, which is never a keyword, field or operator.
+	Quoted bool
+}
+
+// isKeyword reports whether the token is the unquoted keyword, so that e.g. "require" can be a value.
+func (t policyToken) isKeyword(keyword string) bool {
+	return !t.Quoted && t.Text == keyword
+}
+
+// joinPolicyTokens returns tokens as written, for error messages.
+func joinPolicyTokens(tokens []policyToken) string {
+	texts := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Quoted {
+			texts = append(texts, strconv.Quote(token.Text))
+		} else {
+			texts = append(texts, token.Text)
+		}
+	}
+
+	return strings.Join(texts, " ")
+}
+
+// tokenizePolicyLine splits line into words, operators and quoted strings (unquoted).
+func tokenizePolicyLine(line string) ([]policyToken, error) {
+	var tokens []policyToken
+
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string: %s", rest)
+			}
+
+			// QuotedPrefix only returns valid quoted strings.
+			value, _ := strconv.Unquote(quoted)
+			tokens = append(tokens, policyToken{Text: value, Quoted: true})
+			rest = rest[len(quoted):]
+
+			continue
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+
+		tokens = append(tokens, policyToken{Text: rest[:end]})
+		rest = rest[end:]
+	}
+
+	return tokens, nil
+}
+
+// parsePolicyConditions parses conditions joined by "and".
+func parsePolicyConditions(tokens []policyToken) ([]policyCondition, error) {
+	var conditions []policyCondition
+
+	for {
+		if len(tokens) < policyConditionTokens {
+			return nil, fmt.Errorf("incomplete condition: %s", joinPolicyTokens(tokens))
+		}
+
+		condition := policyCondition{Field: tokens[0].Text, Operator: tokens[1].Text, Value: tokens[2].Text}
+
+		_, known := policyFields[condition.Field]
+		if tokens[0].Quoted || (!known && !strings.HasPrefix(condition.Field, PolicyStackTagPrefix)) {
+			return nil, fmt.Errorf("unknown field: %s", joinPolicyTokens(tokens[:1]))
+		}
+
+		switch {
+		case tokens[1].Quoted:
+			return nil, fmt.Errorf("unknown operator: %s", joinPolicyTokens(tokens[1:2]))
+		case condition.Operator == PolicyOperatorEqual, condition.Operator == PolicyOperatorNotEqual:
+		case condition.Operator == PolicyOperatorMatch:
+			if _, err := path.Match(condition.Value, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern: %s", condition.Value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator: %s", condition.Operator)
+		}
+
+		conditions = append(conditions, condition)
+		tokens = tokens[policyConditionTokens:]
+
+		if len(tokens) == 0 {
+			return conditions, nil
+		}
+
+		if !tokens[0].isKeyword(PolicyKeywordAnd) {
+			return nil, fmt.Errorf("expected '%s': %s", PolicyKeywordAnd, joinPolicyTokens(tokens[:1]))
+		}
+
+		tokens = tokens[1:]
+	}
+}
+
+// parsePolicyRule parses a rule line.
+func parsePolicyRule(line string) (policyRule, error) {
+	name, body, ok := strings.Cut(line, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return policyRule{}, fmt.Errorf("missing rule name")
+	}
+
+	tokens, err := tokenizePolicyLine(body)
+	if err != nil {
+		return policyRule{}, err
+	}
+
+	rule := policyRule{Name: strings.TrimSpace(name)}
+
+	requireAt := -1
+
+	for i, token := range tokens {
+		if token.isKeyword(PolicyKeywordRequire) {
+			requireAt = i
+			break
+		}
+	}
+
+	if requireAt < 0 {
+		return policyRule{}, fmt.Errorf("missing '%s'", PolicyKeywordRequire)
+	}
+
+	if requireAt > 0 {
+		if !tokens[0].isKeyword(PolicyKeywordWhen) {
+			return policyRule{}, fmt.Errorf("expected '%s' or '%s': %s", PolicyKeywordWhen, PolicyKeywordRequire,
+				joinPolicyTokens(tokens[:1]))
+		}
+
+		if rule.When, err = parsePolicyConditions(tokens[1:requireAt]); err != nil {
+			return policyRule{}, err
+		}
+	}
+
+	if rule.Require, err = parsePolicyConditions(tokens[requireAt+1:]); err != nil {
+		return policyRule{}, err
+	}
+
+	return rule, nil
+}
+
+// loadResourcePolicy reads and parses a policy file.
+func loadResourcePolicy(filePath string) (*resourcePolicy, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy: %w", err)
+	}
+	defer file.Close()
+
+	policy := &resourcePolicy{}
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule, rerr := parsePolicyRule(line)
+		if rerr != nil {
+			return nil, fmt.Errorf("failed to parse policy line %d: %w", lineNumber, rerr)
+		}
+
+		policy.Rules = append(policy.Rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// firstFailing returns the first condition that does not hold for r, or nil when all of them hold.
+func firstFailing(conditions []policyCondition, r policyResource) *policyCondition {
+	for i := range conditions {
+		if !conditions[i].Holds(r) {
+			return &conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// Evaluate returns the violations of r, one per failed rule.
+func (p *resourcePolicy) Evaluate(r policyResource) []policyViolation {
+	var violations []policyViolation
+
+	for _, rule := range p.Rules {
+		if firstFailing(rule.When, r) != nil {
+			continue
+		}
+
+		failed := firstFailing(rule.Require, r)
+		if failed == nil {
+			continue
+		}
+
+		violations = append(violations, policyViolation{
+			Rule:              rule.Name,
+			Account:           r.Account,
+			Region:            r.Region,
+			StackName:         aws.ToString(r.Stack.StackName),
+			LogicalResourceID: aws.ToString(r.Resource.LogicalResourceId),
+			ResourceType:      aws.ToString(r.Resource.ResourceType),
+			Condition:         failed.String(),
+		})
+	}
+
+	return violations
+}
+
+// printPolicyViolationReport logs the resources that violate the policy.
+func printPolicyViolationReport(violations []policyViolation, policyPath string) {
+	log.Printf("Policy '%s' violations: %d\n", policyPath, len(violations))
+
+	for _, violation := range violations {
+		log.Printf("- %s: %s/%s (%s, %s, %s) fails %s", violation.Rule, violation.StackName, violation.LogicalResourceID,
+			violation.ResourceType, violation.Account, violation.Region, violation.Condition)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestResourcePolicyFixtureViolations(t *testing.T) {
+	logs := captureLog(t)
+
+	policyPath := filepath.Join("testdata", "resource-policy.txt")
+
+	policy, err := loadResourcePolicy(policyPath)
+	if err != nil {
+		t.Fatalf("loadResourcePolicy error: %v", err)
+	}
+
+	if len(policy.Rules) != 3 {
+		t.Fatalf("rules = %+v, want 3", policy.Rules)
+	}
+
+	prod := testStack(testDefaultRegion, "prod-web", cfTypes.StackStatusCreateComplete)
+	dev := testStack(testDefaultRegion, "dev-web", cfTypes.StackStatusCreateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {prod, dev}},
+		tags: map[string]map[string]string{
+			*prod.StackId: {"env": "prod", "team": "web"},
+			*dev.StackId:  {"env": "dev"},
+		},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*prod.StackId: {
+				testResource("Instance", "AWS::EC2::Instance", "i-0prod"),
+				testResource("Assets", "AWS::S3::Bucket", "prod-assets"),
+				testResource("Logs", "AWS::S3::Bucket", "web-logs"),
+			},
+			*dev.StackId: {
+				testResource("Instance", "AWS::EC2::Instance", "i-0dev"),
+				testResource("Assets", "AWS::S3::Bucket", "dev-assets"),
+			},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-policy", policyPath, "-concurrency", "1")
+	s.policy = policy
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	var got []string
+	for _, violation := range s.violations {
+		got = append(got, violation.Rule+" "+violation.StackName+"/"+violation.LogicalResourceID+" "+violation.Condition)
+	}
+
+	want := []string{
+		`named-buckets prod-web/Logs physical_id ~= "prod-*"`,
+		`ec2-in-prod dev-web/Instance stack.tag.env == "prod"`,
+		`owned dev-web/Instance stack.tag.team != ""`,
+		`owned dev-web/Assets stack.tag.team != ""`,
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("violations =\n%q\nwant\n%q", got, want)
+	}
+
+	printPolicyViolationReport(s.violations, policyPath)
+
+	for _, line := range []string{
+		"Policy 'testdata/resource-policy.txt' violations: 4\n",
+		`- ec2-in-prod: dev-web/Instance (AWS::EC2::Instance, 111111111111, us-west-2) fails stack.tag.env == "prod"` + "\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+}
+
+func TestLoadResourcePolicyErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing name":       `: require type == "AWS::S3::Bucket"`,
+		"missing require":    `rule: when type == "AWS::S3::Bucket"`,
+		"unknown field":      `rule: require color == blue`,
+		"unknown operator":   `rule: require type >= "AWS::S3::Bucket"`,
+		"invalid pattern":    `rule: require type ~= "AWS::S3::["`,
+		"incomplete":         `rule: require type ==`,
+		"missing and":        `rule: require type == a region == b`,
+		"unterminated quote": `rule: require type == "AWS::S3::Bucket`,
+		"quoted require":     `rule: when type == a "require" type == b`,
+		"quoted when":        `rule: "when" type == a require type == b`,
+		"quoted and":         `rule: require type == a "and" region == b`,
+		"quoted field":       `rule: require "type" == a`,
+		"quoted operator":    `rule: require type "==" a`,
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			policyPath := filepath.Join(t.TempDir(), "policy.txt")
+			if err := os.WriteFile(policyPath, []byte("# comment\n\n"+line+"\n"), 0o600); err != nil {
+				t.Fatalf("writing policy: %v", err)
+			}
+
+			_, err := loadResourcePolicy(policyPath)
+			if err == nil || !strings.Contains(err.Error(), "line 3") {
+				t.Errorf("loadResourcePolicy(%q) error = %v, want an error on line 3", line, err)
+			}
+		})
+	}
+}
+
+func TestParsePolicyRuleQuotedKeywordsAreValues(t *testing.T) {
+	rule, err := parsePolicyRule(`quoted: when stack.tag.step == "require" and logical_id == "and" require stack.tag.phase != "when"`)
+	if err != nil {
+		t.Fatalf("parsePolicyRule error: %v", err)
+	}
+
+	when := []policyCondition{
+		{Field: "stack.tag.step", Operator: PolicyOperatorEqual, Value: "require"},
+		{Field: "logical_id", Operator: PolicyOperatorEqual, Value: "and"},
+	}
+	require := []policyCondition{{Field: "stack.tag.phase", Operator: PolicyOperatorNotEqual, Value: "when"}}
+
+	if !slices.Equal(rule.When, when) || !slices.Equal(rule.Require, require) {
+		t.Errorf("rule = %+v, want when %v require %v", rule, when, require)
+	}
+}
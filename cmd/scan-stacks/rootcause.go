@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+const (
+	// NestedStackResourceType is the resource type of a nested stack inside its parent, and of a stack in its own
+	// events and in the findings about it.
+	NestedStackResourceType = "AWS::CloudFormation::Stack"
+	// UserInitiatedReason is the status reason of the stack event that starts a stack operation.
+	UserInitiatedReason = "User Initiated"
+)
+
+// stackEventsFunc returns the events of a stack, most recent first.
+type stackEventsFunc func(ctx context.Context, stackID string) ([]cfTypes.StackEvent, error)
+
+// rootCauseStep is one failed resource on the path from a failed root stack to the originating failure.
+type rootCauseStep struct {
+	StackID            string
+	StackName          string
+	LogicalResourceID  string
+	PhysicalResourceID string
+	ResourceType       string
+	ResourceStatus     string
+	Reason             string
+}
+
+// stackRootCause is the chain of failures from a failed root stack down to the deepest failed resource.
+type stackRootCause struct {
+	Region    string
+	StackName string
+	StackID   string
+	Steps     []rootCauseStep
+	// Truncated is set when the walk stopped at -max-depth before reaching the originating failure.
+	Truncated bool
+}
+
+// Deepest returns the originating failure, or nil when no failed resource was found.
+func (c stackRootCause) Deepest() *rootCauseStep {
+	if len(c.Steps) == 0 {
+		return nil
+	}
+
+	return &c.Steps[len(c.Steps)-1]
+}
+
+// cloudFormationStackEventsFunc returns a stackEventsFunc that pages through DescribeStackEvents in the region of cfg.
+func cloudFormationStackEventsFunc(cfg aws.Config) stackEventsFunc {
+	cfClient := cloudformation.NewFromConfig(cfg)
+
+	return func(ctx context.Context, stackID string) ([]cfTypes.StackEvent, error) {
+		var events []cfTypes.StackEvent
+
+		paginator := cloudformation.NewDescribeStackEventsPaginator(cfClient, &cloudformation.DescribeStackEventsInput{
+			StackName: aws.String(stackID),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe stack events: %w", err)
+			}
+
+			events = append(events, page.StackEvents...)
+		}
+
+		return events, nil
+	}
+}
+
+// isFailedOrRolledBackStackStatus reports whether a stack's last operation failed, including
+// operations that were rolled back successfully.
+func isFailedOrRolledBackStackStatus(status cfTypes.StackStatus) bool {
+	return isFailedStackStatus(status) || strings.Contains(string(status), "ROLLBACK")
+}
+
+// operationWindow is the time span of the parent stack operation that a nested stack failed in: from
+// the parent starting the operation on the nested stack resource to the parent recording its failure.
+// Nested stacks have no "User Initiated" event marking the start of their operations, so their events
+// are bounded by this window instead. A nil bound is open.
+type operationWindow struct {
+	Start *time.Time
+	End   *time.Time
+}
+
+// Contains reports whether timestamp falls within the window.
+func (w operationWindow) Contains(timestamp *time.Time) bool {
+	if timestamp == nil {
+		return w.Start == nil && w.End == nil
+	}
+
+	return (w.Start == nil || !timestamp.Before(*w.Start)) && (w.End == nil || !timestamp.After(*w.End))
+}
+
+// nestedStackWindow returns the window of the parent operation whose failure of the nested stack
+// resource is failure. events are the parent stack's events, most recent first.
+func nestedStackWindow(events []cfTypes.StackEvent, failure *cfTypes.StackEvent) operationWindow {
+	window := operationWindow{End: failure.Timestamp}
+
+	for _, event := range events {
+		if aws.ToString(event.LogicalResourceId) != aws.ToString(failure.LogicalResourceId) ||
+			!strings.HasSuffix(string(event.ResourceStatus), "_IN_PROGRESS") || !window.Contains(event.Timestamp) {
+			continue
+		}
+
+		// The most recent start before the failure, as events are ordered most recent first.
+		window.Start = event.Timestamp
+
+		break
+	}
+
+	return window
+}
+
+// firstFailureOfLastOperation returns the earliest resource failure of the stack's most recent
+// operation within window, skipping events of the stack itself. events must be ordered most recent first.
+func firstFailureOfLastOperation(events []cfTypes.StackEvent, window operationWindow) *cfTypes.StackEvent {
+	var first *cfTypes.StackEvent
+
+	for i := range events {
+		event := &events[i]
+		isStackEvent := aws.ToString(event.PhysicalResourceId) == aws.ToString(event.StackId)
+
+		if isStackEvent && aws.ToString(event.ResourceStatusReason) == UserInitiatedReason {
+			break
+		}
+
+		if !window.Contains(event.Timestamp) {
+			continue
+		}
+
+		if !isStackEvent && strings.HasSuffix(string(event.ResourceStatus), "_FAILED") {
+			first = event
+		}
+	}
+
+	return first
+}
+
+// findRootCause walks from the failed stack stackID into its failed nested stacks and returns
+// every failed resource along the way, ending with the deepest one. With a positive maxDepth, at most
+// maxDepth levels of nested stacks are walked, and truncated reports that the walk stopped there.
+func findRootCause(ctx context.Context, getEvents stackEventsFunc, stackID string, maxDepth int) ([]rootCauseStep, bool, error) {
+	var steps []rootCauseStep
+
+	visited := map[string]bool{}
+	window := operationWindow{}
+
+	for depth := 0; stackID != "" && !visited[stackID]; depth++ {
+		if maxDepth > 0 && depth > maxDepth {
+			return steps, true, nil
+		}
+
+		visited[stackID] = true
+
+		events, err := getEvents(ctx, stackID)
+		if err != nil {
+			return steps, false, err
+		}
+
+		failure := firstFailureOfLastOperation(events, window)
+		if failure == nil {
+			break
+		}
+
+		step := rootCauseStep{
+			StackID:            stackID,
+			StackName:          aws.ToString(failure.StackName),
+			LogicalResourceID:  aws.ToString(failure.LogicalResourceId),
+			PhysicalResourceID: aws.ToString(failure.PhysicalResourceId),
+			ResourceType:       aws.ToString(failure.ResourceType),
+			ResourceStatus:     string(failure.ResourceStatus),
+			Reason:             aws.ToString(failure.ResourceStatusReason),
+		}
+		steps = append(steps, step)
+
+		stackID = ""
+		if step.ResourceType == NestedStackResourceType {
+			stackID = step.PhysicalResourceID
+			window = nestedStackWindow(events, failure)
+		}
+	}
+
+	return steps, false, nil
+}
+
+// findStackRootCauses returns the root cause of every failed root stack in region, walking at most
+// maxDepth levels of nested stacks when maxDepth is positive.
+func findStackRootCauses(ctx context.Context, getEvents stackEventsFunc, region string, stacks []cfTypes.StackSummary, maxDepth int) []stackRootCause {
+	var causes []stackRootCause
+
+	for _, stack := range stacks {
+		if stack.ParentId != nil || !isFailedOrRolledBackStackStatus(stack.StackStatus) {
+			continue
+		}
+
+		steps, truncated, err := findRootCause(ctx, getEvents, aws.ToString(stack.StackId), maxDepth)
+		if err != nil {
+			log.Printf("Error finding root cause of stack %s: %v", aws.ToString(stack.StackName), err)
+		}
+
+		causes = append(causes, stackRootCause{
+			Region:    region,
+			StackName: aws.ToString(stack.StackName),
+			StackID:   aws.ToString(stack.StackId),
+			Steps:     steps,
+			Truncated: truncated,
+		})
+	}
+
+	return causes
+}
+
+// printRootCauseReport logs the failure chain and originating failure of each failed root stack.
+func printRootCauseReport(causes []stackRootCause) {
+	log.Printf("Root causes of failed stacks: %d\n", len(causes))
+
+	for _, cause := range causes {
+		log.Printf("- %s (%s)", cause.StackName, cause.Region)
+
+		deepest := cause.Deepest()
+		if deepest == nil {
+			log.Println("  - Root cause: no failed resource found in the last operation")
+			continue
+		}
+
+		for _, step := range cause.Steps {
+			log.Printf("  - %s/%s (%s): %s", step.StackName, step.LogicalResourceID, step.ResourceType, step.ResourceStatus)
+		}
+
+		if cause.Truncated {
+			log.Println("  - Truncated: nested stacks beyond -max-depth were not walked, the root cause may be deeper")
+			log.Printf("  - Deepest failure walked: %s/%s: %s", deepest.StackName, deepest.LogicalResourceID, deepest.Reason)
+
+			continue
+		}
+
+		log.Printf("  - Root cause: %s/%s: %s", deepest.StackName, deepest.LogicalResourceID, deepest.Reason)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// testEvents holds the events of each stack of a fixture by stack id, most recent first.
+type testEvents map[string][]cfTypes.StackEvent
+
+func (e testEvents) get(_ context.Context, stackID string) ([]cfTypes.StackEvent, error) {
+	events, ok := e[stackID]
+	if !ok {
+		return nil, fmt.Errorf("stack %s not found", stackID)
+	}
+
+	return events, nil
+}
+
+// testStackEvent returns an event of stack at minute of testTime. A resource of type NestedStackResourceType
+// has the nested stack id as physical id; the stack itself is the resource with logicalID == stackName.
+func testStackEvent(stackName string, minute int, logicalID string, resourceType string, status cfTypes.ResourceStatus, reason string) cfTypes.StackEvent {
+	stackID := eventStackID(stackName)
+
+	physicalID := logicalID
+	if logicalID == stackName {
+		physicalID = stackID
+	} else if resourceType == NestedStackResourceType {
+		physicalID = eventStackID(logicalID)
+	}
+
+	event := cfTypes.StackEvent{
+		StackId:            aws.String(stackID),
+		StackName:          aws.String(stackName),
+		LogicalResourceId:  aws.String(logicalID),
+		PhysicalResourceId: aws.String(physicalID),
+		ResourceType:       aws.String(resourceType),
+		ResourceStatus:     status,
+		Timestamp:          aws.Time(testTime.Add(time.Duration(minute) * time.Minute)),
+	}
+
+	if reason != "" {
+		event.ResourceStatusReason = aws.String(reason)
+	}
+
+	return event
+}
+
+// eventStackID returns the id of a fixture stack, as testStack sets it.
+func eventStackID(stackName string) string {
+	return aws.ToString(testStack(testDefaultRegion, stackName, "").StackId)
+}
+
+// nestedFailureEvents is a root stack "app" whose update failed because the Subnet of its nested stack
+// "network" failed. Both stacks also hold failures of an earlier operation.
+func nestedFailureEvents() testEvents {
+	return testEvents{
+		eventStackID("app"): {
+			testStackEvent("app", 20, "app", NestedStackResourceType, cfTypes.ResourceStatus("UPDATE_ROLLBACK_COMPLETE"), ""),
+			testStackEvent("app", 12, "network", NestedStackResourceType, cfTypes.ResourceStatusUpdateFailed,
+				"Embedded stack "+eventStackID("network")+" was not successfully updated"),
+			testStackEvent("app", 5, "network", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+			testStackEvent("app", 4, "app", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, UserInitiatedReason),
+			testStackEvent("app", -100, "Database", "AWS::RDS::DBInstance", cfTypes.ResourceStatusCreateFailed, "old root failure"),
+			testStackEvent("app", -200, "app", NestedStackResourceType, cfTypes.ResourceStatusCreateInProgress, UserInitiatedReason),
+		},
+		eventStackID("network"): {
+			testStackEvent("network", 13, "network", NestedStackResourceType, cfTypes.ResourceStatus("UPDATE_ROLLBACK_IN_PROGRESS"), ""),
+			testStackEvent("network", 10, "RouteTable", "AWS::EC2::RouteTable", cfTypes.ResourceStatusUpdateFailed, "Resource update cancelled"),
+			testStackEvent("network", 8, "Subnet", "AWS::EC2::Subnet", cfTypes.ResourceStatusUpdateFailed,
+				"The CIDR '10.0.0.0/24' conflicts with another subnet"),
+			testStackEvent("network", 6, "network", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+			testStackEvent("network", -50, "Vpc", "AWS::EC2::VPC", cfTypes.ResourceStatusUpdateFailed, "old nested failure"),
+			testStackEvent("network", -60, "network", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+		},
+	}
+}
+
+func TestFindRootCauseReportsDeepestNestedFailure(t *testing.T) {
+	steps, truncated, err := findRootCause(context.Background(), nestedFailureEvents().get, eventStackID("app"), 0)
+	if err != nil {
+		t.Fatalf("findRootCause error: %v", err)
+	}
+
+	if truncated || len(steps) != 2 {
+		t.Fatalf("steps = %+v (truncated %v), want 2 steps", steps, truncated)
+	}
+
+	if steps[0].LogicalResourceID != "network" || steps[0].ResourceType != NestedStackResourceType {
+		t.Errorf("first step = %+v, want the nested stack resource network", steps[0])
+	}
+
+	deepest := stackRootCause{Steps: steps}.Deepest()
+	if deepest.StackName != "network" || deepest.LogicalResourceID != "Subnet" ||
+		deepest.Reason != "The CIDR '10.0.0.0/24' conflicts with another subnet" {
+		t.Errorf("deepest failure = %+v, want network/Subnet and its CIDR conflict", deepest)
+	}
+}
+
+func TestFindRootCauseIgnoresNestedFailuresOfEarlierOperations(t *testing.T) {
+	events := nestedFailureEvents()
+
+	// Without events of the current operation, only the earlier failure is left in the nested stack.
+	network := events[eventStackID("network")]
+	events[eventStackID("network")] = network[len(network)-2:]
+
+	steps, _, err := findRootCause(context.Background(), events.get, eventStackID("app"), 0)
+	if err != nil {
+		t.Fatalf("findRootCause error: %v", err)
+	}
+
+	if len(steps) != 1 || steps[0].LogicalResourceID != "network" {
+		t.Errorf("steps = %+v, want only the nested stack resource network", steps)
+	}
+}
+
+func TestFindStackRootCausesOnlyFailedRootStacks(t *testing.T) {
+	captureLog(t)
+
+	nested := testStack(testDefaultRegion, "network", cfTypes.StackStatusUpdateRollbackComplete)
+	nested.ParentId = aws.String(eventStackID("app"))
+
+	stacks := []cfTypes.StackSummary{
+		{StackId: aws.String(eventStackID("app")), StackName: aws.String("app"), StackStatus: cfTypes.StackStatusUpdateRollbackComplete},
+		nested,
+		testStack(testDefaultRegion, "healthy", cfTypes.StackStatusUpdateComplete),
+	}
+
+	causes := findStackRootCauses(context.Background(), nestedFailureEvents().get, testDefaultRegion, stacks, 0)
+	if len(causes) != 1 || causes[0].StackName != "app" || causes[0].Deepest().LogicalResourceID != "Subnet" {
+		t.Errorf("root causes = %+v, want app caused by Subnet", causes)
+	}
+}
+
+// deepFailureEvents extends nestedFailureEvents by a level: the subnet of "network" is in its nested stack
+// "subnets", whose Subnet failed.
+func deepFailureEvents() testEvents {
+	events := nestedFailureEvents()
+
+	events[eventStackID("network")] = []cfTypes.StackEvent{
+		testStackEvent("network", 13, "network", NestedStackResourceType, cfTypes.ResourceStatus("UPDATE_ROLLBACK_IN_PROGRESS"), ""),
+		testStackEvent("network", 10, "RouteTable", "AWS::EC2::RouteTable", cfTypes.ResourceStatusUpdateFailed, "Resource update cancelled"),
+		testStackEvent("network", 8, "subnets", NestedStackResourceType, cfTypes.ResourceStatusUpdateFailed,
+			"Embedded stack "+eventStackID("subnets")+" was not successfully updated"),
+		testStackEvent("network", 7, "subnets", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+		testStackEvent("network", 6, "network", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+	}
+
+	events[eventStackID("subnets")] = []cfTypes.StackEvent{
+		testStackEvent("subnets", 8, "Subnet", "AWS::EC2::Subnet", cfTypes.ResourceStatusUpdateFailed,
+			"The CIDR '10.0.0.0/24' conflicts with another subnet"),
+		testStackEvent("subnets", 7, "subnets", NestedStackResourceType, cfTypes.ResourceStatusUpdateInProgress, ""),
+	}
+
+	return events
+}
+
+func TestFindRootCauseTruncatesAtMaxDepth(t *testing.T) {
+	tests := []struct {
+		maxDepth  int
+		truncated bool
+		deepest   string
+	}{
+		{0, false, "subnets/Subnet"},
+		{1, true, "network/subnets"},
+		{2, false, "subnets/Subnet"},
+		{3, false, "subnets/Subnet"},
+	}
+
+	for _, tt := range tests {
+		steps, truncated, err := findRootCause(context.Background(), deepFailureEvents().get, eventStackID("app"), tt.maxDepth)
+		if err != nil {
+			t.Fatalf("max depth %d: findRootCause error: %v", tt.maxDepth, err)
+		}
+
+		deepest := stackRootCause{Steps: steps}.Deepest()
+		if truncated != tt.truncated || deepest == nil || deepest.StackName+"/"+deepest.LogicalResourceID != tt.deepest {
+			t.Errorf("max depth %d: steps = %+v (truncated %v), want the deepest failure %s (truncated %v)",
+				tt.maxDepth, steps, truncated, tt.deepest, tt.truncated)
+		}
+	}
+}
+
+func TestRootCauseReportMarksTruncation(t *testing.T) {
+	logs := captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks: map[string][]cfTypes.StackSummary{testDefaultRegion: {
+			{StackId: aws.String(eventStackID("app")), StackName: aws.String("app"), StackStatus: cfTypes.StackStatusUpdateRollbackComplete},
+		}},
+		events: deepFailureEvents(),
+	}
+	s := newTestScanner(t, account, false, "-root-cause", "-max-depth", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	printRootCauseReport(s.rootCauses)
+
+	for _, line := range []string{
+		"  - Truncated: nested stacks beyond -max-depth were not walked, the root cause may be deeper\n",
+		"  - Deepest failure walked: network/subnets: Embedded stack " + eventStackID("subnets") + " was not successfully updated\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+
+	if strings.Contains(logs.String(), "Root cause:") {
+		t.Errorf("a truncated analysis reports a root cause:\n%s", logs)
+	}
+}
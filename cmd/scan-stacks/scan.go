@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// scanner walks the regions of one or more accounts and accumulates what the enabled reports need.
+type scanner struct {
+	opts     *options
+	verbose  bool
+	emitter  *eventEmitter
+	tfState  *terraformState
+	scanTime time.Time
+	format   formatter
+	template *resourceTemplate
+	kafka    *kafkaPublisher
+	policy   *resourcePolicy
+
+	// listRegions and listZones are the EC2 calls listing the regions of an account and the zones of a region.
+	listRegions regionsFunc
+	listZones   zonesFunc
+	// listStacks, listStackTags and listStackResources are the CloudFormation listing calls of the scan pipeline.
+	listStacks         stacksFunc
+	listStackTags      stackTagsFunc
+	listStackResources stackResourcesFunc
+	// stackEvents returns the DescribeStackEvents call of a region, for -root-cause and -resource-timeline.
+	stackEvents regionStackEventsFunc
+
+	// textOutput logs stacks and resources while scanning.
+	textOutput bool
+	// report collects the scanned stacks for the structured outputs, the manifest, the cache, the baseline comparison
+	// and the interactive picker; it is nil otherwise.
+	report *scanReport
+
+	staleDrift       []staleDriftStack
+	findings         []stackFinding
+	scannedResources []scannedResource
+	resourceTypes    map[string]bool
+	rootCauses       []stackRootCause
+	inProgress       []inProgressStack
+	statusCounts     []stackStatusCounts
+	missingDeps      []missingDependencyResource
+	affected         *affectedStacks
+	shared           *sharedResourceFinder
+	tagGroups        []tagGroupStack
+	violations       []policyViolation
+	noPhysicalID     []noPhysicalIDResource
+	// emptyRegions are the regions without stacks left out of the text output with -only-regions-with-stacks.
+	emptyRegions []string
+}
+
+// newScanner returns a scanner for the given options.
+func newScanner(opts *options, verbose bool, emitter *eventEmitter, tfState *terraformState) *scanner {
+	s := &scanner{
+		opts:          opts,
+		verbose:       verbose,
+		emitter:       emitter,
+		tfState:       tfState,
+		scanTime:      time.Now(),
+		format:        opts.formatter(),
+		textOutput:    opts.output == OutputText,
+		resourceTypes: map[string]bool{},
+
+		listRegions:        getAWSRegions,
+		listZones:          getAvailabilityZones,
+		listStacks:         cloudFormationListStacks,
+		listStackTags:      cloudFormationStackTags,
+		listStackResources: cloudFormationListStackResources,
+		stackEvents:        cloudFormationStackEventsFunc,
+	}
+
+	if opts.affectedBy != "" {
+		s.affected = newAffectedStacks(opts.affectedBy)
+	}
+
+	if opts.sharedResource != "" {
+		s.shared = newSharedResourceFinder(opts.sharedResource, opts.sharedResourceType)
+	}
+
+	if (!s.textOutput && opts.output != OutputOCSF) || opts.interactive || opts.manifestPath != "" || opts.cacheDir != "" ||
+		opts.baselinePath != "" {
+		s.report = &scanReport{GeneratedAt: s.scanTime, Regions: []regionReport{}}
+	}
+
+	return s
+}
+
+// newThrottle returns a new adaptive throttle for the resource listing of one region, or nil without -max-rps.
+func (s *scanner) newThrottle() *adaptiveThrottle {
+	if s.opts.maxRPS <= 0 {
+		return nil
+	}
+
+	// The range was validated by parseOptions.
+	throttle, _ := newAdaptiveThrottle(s.opts.minRPS, s.opts.maxRPS)
+
+	return throttle
+}
+
+// emit writes event to the scan event stream, if one is enabled, and publishes stack and resource
+// events to Kafka, if enabled.
+func (s *scanner) emit(event scanEvent) {
+	if err := s.emitter.Emit(event); err != nil {
+		log.Fatalf("Unable to emit scan event: %v", err)
+	}
+
+	if s.kafka != nil && (event.Type == EventStackFound || event.Type == EventResourceFound) {
+		s.kafka.Publish(event)
+	}
+}
+
+// useCachedReport takes report from the cache instead of scanning, logging its stacks as a scan would.
+func (s *scanner) useCachedReport(report *scanReport) {
+	s.report = report
+
+	if !s.verbose || !s.textOutput {
+		return
+	}
+
+	for _, region := range report.Regions {
+		if s.opts.onlyRegionsWithStacks && len(region.Stacks) == 0 {
+			s.emptyRegions = append(s.emptyRegions, regionCoverageKey(region.Account, region.Region))
+			continue
+		}
+
+		log.Printf("- Region: %s\n", region.Region)
+
+		for _, stack := range region.Stacks {
+			s.format.logStack(region.Region, stack)
+
+			for _, resource := range stack.Resources {
+				s.format.logResource(region.Region, stack.StackName, resource)
+			}
+		}
+	}
+}
+
+// logEndpoints logs the CloudFormation endpoint resolved for each region of the target account.
+func (s *scanner) logEndpoints(ctx context.Context, target scanTarget, regionNames []string) {
+	for _, regionName := range regionNames {
+		endpoint, err := cloudFormationEndpoint(ctx, target.ConfigForRegion(regionName))
+		if err != nil {
+			log.Printf("Error resolving endpoint for region '%s': %v", regionName, err)
+			continue
+		}
+
+		log.Printf("CloudFormation endpoint for region '%s': %s\n", regionName, endpoint)
+	}
+}
+
+// scanAccounts scans each target account in turn. An account that cannot be scanned, e.g. because its role
+// cannot be assumed, is logged and skipped, so the other accounts of an -org scan are still scanned.
+func (s *scanner) scanAccounts(ctx context.Context, targets []scanTarget, defaultRegion string) {
+	for _, target := range targets {
+		log.Printf("Account: %s\n", target.AccountID)
+
+		if err := s.scanAccount(ctx, target, defaultRegion); err != nil {
+			log.Printf("Error scanning account %s: %v", target.AccountID, err)
+		}
+	}
+}
+
+// scanAccount scans every region enabled in the target account once, starting with defaultRegion.
+func (s *scanner) scanAccount(ctx context.Context, target scanTarget, defaultRegion string) error {
+	account := target.AccountID
+
+	regions, rerr := s.listRegions(ctx, target.ConfigForRegion(defaultRegion), false)
+	if rerr != nil {
+		return fmt.Errorf("unable to load AWS Regions: %w", rerr)
+	}
+
+	allRegionNames := []string{defaultRegion} // Add more regions if needed
+
+	for _, region := range *regions {
+		if region.RegionName != nil && !slices.Contains(allRegionNames, *region.RegionName) {
+			if s.verbose {
+				log.Printf("Adding region '%s' (%s)\n", *region.RegionName, NilSafeString(region.Endpoint))
+			}
+
+			allRegionNames = append(allRegionNames, *region.RegionName)
+		}
+	}
+
+	log.Printf("All detected AWS Regions: %v\n", allRegionNames)
+
+	if s.opts.shard.Count > 1 {
+		allRegionNames = slices.DeleteFunc(allRegionNames, func(regionName string) bool {
+			return !s.opts.shard.Includes(account, regionName)
+		})
+
+		log.Printf("AWS Regions in shard %s: %v\n", s.opts.shard, allRegionNames)
+	}
+
+	if s.opts.showEndpoints {
+		s.logEndpoints(ctx, target, allRegionNames)
+	}
+
+	log.Println("Checking each region for stacks...")
+
+	s.scanRegions(ctx, target, allRegionNames)
+
+	return nil
+}
+
+// addStackResource feeds a single stack resource to the enabled reports and to the report entry of its stack.
+func (s *scanner) addStackResource(account string, regionName string, stack cfTypes.StackSummary, stackResource cfTypes.StackResourceSummary,
+	timeline *resourceTimeline, entry *stackReport,
+) {
+	s.emit(scanEvent{Type: EventResourceFound, Account: account, Region: regionName, Resource: &eventResource{
+		StackName:          aws.ToString(stack.StackName),
+		LogicalResourceID:  aws.ToString(stackResource.LogicalResourceId),
+		PhysicalResourceID: aws.ToString(stackResource.PhysicalResourceId),
+		ResourceType:       aws.ToString(stackResource.ResourceType),
+		ResourceStatus:     string(stackResource.ResourceStatus),
+	}})
+
+	if s.tfState != nil {
+		s.scannedResources = append(s.scannedResources, scannedResource{
+			Region:             regionName,
+			StackName:          NilSafeString(stack.StackName),
+			LogicalResourceID:  NilSafeString(stackResource.LogicalResourceId),
+			PhysicalResourceID: aws.ToString(stackResource.PhysicalResourceId),
+			ResourceType:       NilSafeString(stackResource.ResourceType),
+		})
+	}
+
+	if s.opts.missingDeps {
+		if missing := findMissingDependency(account, regionName, stack, stackResource); missing != nil {
+			s.missingDeps = append(s.missingDeps, *missing)
+		}
+	}
+
+	if s.opts.noPhysicalID {
+		if missing := findNoPhysicalID(account, regionName, stack, stackResource); missing != nil {
+			s.noPhysicalID = append(s.noPhysicalID, *missing)
+		}
+	}
+
+	if s.policy != nil {
+		s.violations = append(s.violations, s.policy.Evaluate(policyResource{
+			Account:   account,
+			Region:    regionName,
+			Stack:     stack,
+			StackTags: entry.Tags,
+			Resource:  stackResource,
+		})...)
+	}
+
+	if s.affected != nil {
+		s.affected.Add(account, regionName, stack, stackResource)
+	}
+
+	if s.shared != nil {
+		s.shared.Add(account, regionName, stack, stackResource)
+	}
+
+	if s.opts.serviceMap && stackResource.ResourceType != nil {
+		s.resourceTypes[*stackResource.ResourceType] = true
+	}
+
+	if s.template != nil {
+		if err := s.template.Render(account, regionName, stack, stackResource); err != nil {
+			log.Fatalf("Unable to render resource template: %v", err)
+		}
+	}
+
+	resource := newResourceReport(stackResource)
+	resource.Timeline = timeline
+
+	if entry.ResourceStatusCounts != nil {
+		entry.ResourceStatusCounts[resource.ResourceStatus]++
+	}
+
+	if s.verbose && s.textOutput {
+		s.format.logResource(regionName, entry.StackName, resource)
+	}
+
+	// Resources are only held for the reports; the text output streams them.
+	if s.report != nil {
+		entry.Resources = append(entry.Resources, resource)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	testAccount       = "111111111111"
+	testDefaultRegion = "us-west-2"
+)
+
+// fakeAccount stands in for the EC2 and CloudFormation listing calls of an account.
+type fakeAccount struct {
+	mu sync.Mutex
+	// regions are the regions returned by DescribeRegions.
+	regions []string
+	// zones are the availability zones of each region.
+	zones map[string][]ec2Types.AvailabilityZone
+	// stacks are the stacks of each region.
+	stacks map[string][]cfTypes.StackSummary
+	// tags are the tags of each stack, by stack id.
+	tags map[string]map[string]string
+	// resources are the resources of each stack, by stack id.
+	resources map[string][]cfTypes.StackResourceSummary
+	// events are the events of each stack, by stack id, most recent first.
+	events map[string][]cfTypes.StackEvent
+	// pageSize is the number of resources per ListStackResources page; 0 returns a single page.
+	pageSize int
+
+	// listedRegions are the regions ListStacks was called for, in call order.
+	listedRegions []string
+	// calls is the number of API calls made.
+	calls int
+	// throttles are the throttles the resources of each region were listed with.
+	throttles map[string][]*adaptiveThrottle
+}
+
+func (a *fakeAccount) listRegions(_ context.Context, _ aws.Config, _ bool) (*[]ec2Types.Region, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.calls++
+
+	regions := make([]ec2Types.Region, 0, len(a.regions))
+	for _, name := range a.regions {
+		regions = append(regions, ec2Types.Region{RegionName: aws.String(name)})
+	}
+
+	return &regions, nil
+}
+
+func (a *fakeAccount) listZones(_ context.Context, cfg aws.Config) ([]ec2Types.AvailabilityZone, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.calls++
+
+	zones, ok := a.zones[cfg.Region]
+	if !ok {
+		return nil, fmt.Errorf("no availability zones in %s", cfg.Region)
+	}
+
+	return zones, nil
+}
+
+func (a *fakeAccount) listStacks(_ context.Context, cfg aws.Config) (*[]cfTypes.StackSummary, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.calls++
+	a.listedRegions = append(a.listedRegions, cfg.Region)
+
+	stacks := slices.Clone(a.stacks[cfg.Region])
+
+	return &stacks, nil
+}
+
+func (a *fakeAccount) listStackTags(_ context.Context, cfg aws.Config) (map[string]map[string]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.calls++
+
+	tags := map[string]map[string]string{}
+	for _, stack := range a.stacks[cfg.Region] {
+		tags[aws.ToString(stack.StackId)] = a.tags[aws.ToString(stack.StackId)]
+	}
+
+	return tags, nil
+}
+
+func (a *fakeAccount) stackEvents(_ aws.Config) stackEventsFunc {
+	return func(_ context.Context, stackID string) ([]cfTypes.StackEvent, error) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.calls++
+
+		return a.events[stackID], nil
+	}
+}
+
+func (a *fakeAccount) listStackResources(_ context.Context, cfg aws.Config, stackID string, throttle *adaptiveThrottle, onPage stackResourcePageFunc) (*[]cfTypes.StackResourceSummary, error) {
+	a.mu.Lock()
+	if a.throttles == nil {
+		a.throttles = map[string][]*adaptiveThrottle{}
+	}
+	a.throttles[cfg.Region] = append(a.throttles[cfg.Region], throttle)
+	resources := a.resources[stackID]
+	pageSize := a.pageSize
+	a.mu.Unlock()
+
+	if pageSize <= 0 {
+		pageSize = max(len(resources), 1)
+	}
+
+	for start := 0; start < len(resources) || start == 0; start += pageSize {
+		a.mu.Lock()
+		a.calls++
+		a.mu.Unlock()
+
+		page := resources[start:min(start+pageSize, len(resources))]
+		if err := onPage(page); err != nil {
+			return nil, err
+		}
+	}
+
+	return &[]cfTypes.StackResourceSummary{}, nil
+}
+
+// install makes s list regions, zones, stacks, tags, resources and events from the fake account.
+func (a *fakeAccount) install(s *scanner) {
+	s.listRegions = a.listRegions
+	s.listZones = a.listZones
+	s.listStacks = a.listStacks
+	s.listStackTags = a.listStackTags
+	s.listStackResources = a.listStackResources
+	s.stackEvents = a.stackEvents
+}
+
+// testStack returns a stack summary of region with the given name and status.
+func testStack(region string, name string, status cfTypes.StackStatus) cfTypes.StackSummary {
+	return cfTypes.StackSummary{
+		StackId:     aws.String(fmt.Sprintf("arn:aws:cloudformation:%s:%s:stack/%s/id", region, testAccount, name)),
+		StackName:   aws.String(name),
+		StackStatus: status,
+	}
+}
+
+// testResource returns a stack resource summary with the given logical id, type and physical id.
+func testResource(logicalID string, resourceType string, physicalID string) cfTypes.StackResourceSummary {
+	resource := cfTypes.StackResourceSummary{
+		LogicalResourceId: aws.String(logicalID),
+		ResourceType:      aws.String(resourceType),
+		ResourceStatus:    cfTypes.ResourceStatusCreateComplete,
+	}
+
+	if physicalID != "" {
+		resource.PhysicalResourceId = aws.String(physicalID)
+	}
+
+	return resource
+}
+
+// newTestScanner returns a scanner for the command line args, listing from account.
+func newTestScanner(t testing.TB, account *fakeAccount, verbose bool, args ...string) *scanner {
+	t.Helper()
+
+	opts, err := parseOptions("scan-stacks", args)
+	if err != nil {
+		t.Fatalf("parseOptions(%v) error: %v", args, err)
+	}
+
+	s := newScanner(opts, verbose, nil, nil)
+	account.install(s)
+
+	return s
+}
+
+// captureLog redirects the standard logger to the returned buffer for the rest of the test.
+func captureLog(t testing.TB) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	})
+
+	return &buf
+}
+
+func TestScanAccountScansDefaultRegionOnce(t *testing.T) {
+	captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{"us-east-1", testDefaultRegion, "eu-west-1"},
+		stacks: map[string][]cfTypes.StackSummary{
+			testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)},
+		},
+	}
+	s := newTestScanner(t, account, false, "-output", "json")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	if want := []string{testDefaultRegion, "us-east-1", "eu-west-1"}; !slices.Equal(account.listedRegions, want) {
+		t.Errorf("listed regions = %v, want %v", account.listedRegions, want)
+	}
+
+	var regions []string
+	for _, region := range s.report.Regions {
+		regions = append(regions, region.Region)
+	}
+
+	if want := []string{testDefaultRegion, "us-east-1", "eu-west-1"}; !slices.Equal(regions, want) {
+		t.Errorf("report regions = %v, want %v", regions, want)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Resource scopes reported by the service map.
+const (
+	ScopeRegional = "regional"
+	ScopeGlobal   = "global"
+)
+
+// serviceNamespaceOverrides maps CloudFormation namespaces whose IAM service prefix is not simply the lowercased namespace.
+var serviceNamespaceOverrides = map[string]string{
+	"ApiGatewayV2":           "apigateway",
+	"CertificateManager":     "acm",
+	"Cognito":                "cognito-idp",
+	"ElasticLoadBalancingV2": "elasticloadbalancing",
+	"KinesisFirehose":        "firehose",
+	"StepFunctions":          "states",
+}
+
+// globalServices are the services whose resources are not bound to a region.
+var globalServices = map[string]bool{
+	"cloudfront":        true,
+	"globalaccelerator": true,
+	"iam":               true,
+	"organizations":     true,
+	"route53":           true,
+	"waf":               true,
+}
+
+// serviceMapEntry maps a CloudFormation resource type to the AWS service that owns it.
+type serviceMapEntry struct {
+	ResourceType string `json:"resourceType"`
+	Service      string `json:"service"`
+	Scope        string `json:"scope"`
+}
+
+// resourceTypeService derives the AWS service (IAM prefix) and scope of a resource type from its namespace,
+// e.g. AWS::S3::Bucket -> s3. Custom resources are attributed to CloudFormation.
+func resourceTypeService(resourceType string) serviceMapEntry {
+	entry := serviceMapEntry{ResourceType: resourceType, Scope: ScopeRegional}
+
+	parts := strings.Split(resourceType, "::")
+
+	switch {
+	case parts[0] == "Custom":
+		entry.Service = "cloudformation"
+	case len(parts) == 1:
+		entry.Service = strings.ToLower(resourceType)
+	default:
+		entry.Service = strings.ToLower(parts[1])
+		if service, ok := serviceNamespaceOverrides[parts[1]]; ok {
+			entry.Service = service
+		}
+	}
+
+	if globalServices[entry.Service] {
+		entry.Scope = ScopeGlobal
+	}
+
+	return entry
+}
+
+// buildServiceMap returns the service map entries for the unique resource types, sorted by type.
+func buildServiceMap(resourceTypes map[string]bool) []serviceMapEntry {
+	entries := make([]serviceMapEntry, 0, len(resourceTypes))
+
+	for resourceType := range resourceTypes {
+		entries = append(entries, resourceTypeService(resourceType))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ResourceType < entries[j].ResourceType
+	})
+
+	return entries
+}
+
+// writeServiceMap writes the service map as tab separated type, service and scope lines.
+func writeServiceMap(w io.Writer, entries []serviceMapEntry) error {
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", entry.ResourceType, entry.Service, entry.Scope); err != nil {
+			return fmt.Errorf("failed to write service map: %w", err)
+		}
+	}
+
+	return nil
+}
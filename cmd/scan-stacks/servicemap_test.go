@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuildServiceMap(t *testing.T) {
+	resourceTypes := map[string]bool{
+		"AWS::S3::Bucket": true,
+		"AWS::IAM::Role":  true,
+		"AWS::ElasticLoadBalancingV2::LoadBalancer": true,
+		"AWS::StepFunctions::StateMachine":          true,
+		"AWS::CloudFront::Distribution":             true,
+		"Custom::CertificateValidator":              true,
+		"AWS::EC2::SecurityGroup":                   true,
+	}
+
+	var buf bytes.Buffer
+	if err := writeServiceMap(&buf, buildServiceMap(resourceTypes)); err != nil {
+		t.Fatalf("writeServiceMap error: %v", err)
+	}
+
+	want := "AWS::CloudFront::Distribution\tcloudfront\tglobal\n" +
+		"AWS::EC2::SecurityGroup\tec2\tregional\n" +
+		"AWS::ElasticLoadBalancingV2::LoadBalancer\telasticloadbalancing\tregional\n" +
+		"AWS::IAM::Role\tiam\tglobal\n" +
+		"AWS::S3::Bucket\ts3\tregional\n" +
+		"AWS::StepFunctions::StateMachine\tstates\tregional\n" +
+		"Custom::CertificateValidator\tcloudformation\tregional\n"
+	if got := buf.String(); got != want {
+		t.Errorf("service map =\n%s\nwant\n%s", got, want)
+	}
+}
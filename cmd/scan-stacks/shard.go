@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// shard selects a deterministic subset of the account/region pairs so that N CI jobs
+// can each scan a disjoint part of a large fleet.
+type shard struct {
+	Index int
+	Count int
+}
+
+// parseShard parses a shard specification of the form i/N, where 0 <= i < N.
+func parseShard(value string) (shard, error) {
+	indexText, countText, ok := strings.Cut(value, "/")
+	if !ok {
+		return shard{}, fmt.Errorf("shard must be of the form i/N: %s", value)
+	}
+
+	index, err := strconv.Atoi(indexText)
+	if err != nil {
+		return shard{}, fmt.Errorf("invalid shard index '%s': %w", indexText, err)
+	}
+
+	count, err := strconv.Atoi(countText)
+	if err != nil {
+		return shard{}, fmt.Errorf("invalid shard count '%s': %w", countText, err)
+	}
+
+	if count < 1 || index < 0 || index >= count {
+		return shard{}, fmt.Errorf("shard index must be in [0, N) and N at least 1: %s", value)
+	}
+
+	return shard{Index: index, Count: count}, nil
+}
+
+// String returns the shard in its i/N form.
+func (s shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Includes reports whether the account/region pair belongs to this shard. The zero shard includes everything.
+func (s shard) Includes(account string, region string) bool {
+	if s.Count <= 1 {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(account + "/" + region))
+
+	return int(hash.Sum32()%uint32(s.Count)) == s.Index
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestShardsAreDisjointAndExhaustive(t *testing.T) {
+	regions := []string{"us-east-1", "us-east-2", "us-west-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-2", "sa-east-1"}
+
+	for _, count := range []int{1, 2, 3, 5} {
+		owners := map[string]int{}
+
+		for account := range 20 {
+			for _, region := range regions {
+				pair := fmt.Sprintf("%012d/%s", account, region)
+
+				for index := range count {
+					if (shard{Index: index, Count: count}).Includes(fmt.Sprintf("%012d", account), region) {
+						owners[pair]++
+					}
+				}
+			}
+		}
+
+		if len(owners) != 20*len(regions) {
+			t.Errorf("%d shards cover %d pairs, want %d", count, len(owners), 20*len(regions))
+		}
+
+		for pair, shards := range owners {
+			if shards != 1 {
+				t.Errorf("%d shards: %s is in %d shards, want 1", count, pair, shards)
+			}
+		}
+	}
+}
+
+func TestScanAccountShardsCoverEveryRegionOnce(t *testing.T) {
+	captureLog(t)
+
+	regions := []string{"us-east-1", "us-east-2", testDefaultRegion, "eu-west-1", "ap-northeast-1"}
+
+	var covered []string
+
+	for index := range 3 {
+		account := &fakeAccount{regions: regions}
+		s := newTestScanner(t, account, false, "-shard", fmt.Sprintf("%d/3", index))
+
+		if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+			t.Fatalf("scanAccount error: %v", err)
+		}
+
+		covered = append(covered, account.listedRegions...)
+	}
+
+	slices.Sort(covered)
+	if want := slices.Sorted(slices.Values(regions)); !slices.Equal(covered, want) {
+		t.Errorf("regions scanned by the shards = %v, want %v", covered, want)
+	}
+}
+
+func TestParseShard(t *testing.T) {
+	if got, err := parseShard("2/4"); err != nil || got != (shard{Index: 2, Count: 4}) {
+		t.Errorf("parseShard(2/4) = %v, %v", got, err)
+	}
+
+	for _, value := range []string{"4/4", "-1/2", "1", "a/2", "0/0"} {
+		if _, err := parseShard(value); err == nil {
+			t.Errorf("parseShard(%q) returned no error", value)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// sharedResourceReference is a stack resource referencing the physical id given with -shared-resource.
+type sharedResourceReference struct {
+	Account            string
+	Region             string
+	StackName          string
+	StackStatus        string
+	LogicalResourceID  string
+	ResourceType       string
+	PhysicalResourceID string
+	ResourceStatus     string
+}
+
+// sharedResourceFinder collects, in scan order, every stack resource referencing a physical id. Besides
+// the exact id, an ARN matches the resources referring to the same resource of its account and region by
+// id or name, and an id or name matches the resources referring by ARN to the resource of that name in
+// their own account and region.
+type sharedResourceFinder struct {
+	physicalID string
+	// resourceType, when set, is the only resource type that matches.
+	resourceType string
+	normalized   normalizedResourceID
+	isARN        bool
+	// account and region are those of an ARN physical id; either is empty when the ARN leaves it out.
+	account    string
+	region     string
+	references []sharedResourceReference
+}
+
+// newSharedResourceFinder returns a finder for the stack resources referencing physicalID, of resourceType
+// when it is not empty.
+func newSharedResourceFinder(physicalID string, resourceType string) *sharedResourceFinder {
+	finder := &sharedResourceFinder{physicalID: physicalID, resourceType: resourceType}
+	finder.normalized, _ = normalizePhysicalID("", physicalID)
+
+	if parsed, err := arn.Parse(physicalID); err == nil {
+		finder.isARN = true
+		finder.account, finder.region = parsed.AccountID, parsed.Region
+	}
+
+	return finder
+}
+
+// matches reports whether a resource of resourceType with physicalID, in a stack of account and region,
+// references the resource of the finder.
+func (f *sharedResourceFinder) matches(account string, region string, resourceType string, physicalID string) bool {
+	if f.resourceType != "" && resourceType != f.resourceType {
+		return false
+	}
+
+	if physicalID == f.physicalID {
+		return true
+	}
+
+	normalized, ok := normalizePhysicalID(resourceType, physicalID)
+	if !ok {
+		return false
+	}
+
+	// The resource refers to the account and region its ARN names, or else to those of its stack.
+	refAccount, refRegion := account, region
+	if parsed, err := arn.Parse(physicalID); err == nil {
+		refAccount, refRegion = parsed.AccountID, parsed.Region
+	}
+
+	if f.isARN {
+		if !sameARNScope(f.account, refAccount) || !sameARNScope(f.region, refRegion) {
+			return false
+		}
+
+		// ARNs without a type, e.g. of S3 buckets, match the resources of the service with the same id.
+		return normalized.Service == f.normalized.Service && normalized.ID == f.normalized.ID &&
+			(f.normalized.Type == "" || normalized.Type == f.normalized.Type)
+	}
+
+	// An id or name stands for the resource of the stack's own account and region, not one named alike elsewhere.
+	if !sameARNScope(account, refAccount) || !sameARNScope(region, refRegion) {
+		return false
+	}
+
+	return normalized.ID == f.physicalID
+}
+
+// sameARNScope reports whether two ARN accounts, or regions, can be the same. An empty one, e.g. the region
+// of a global resource or the account of an S3 bucket, matches any.
+func sameARNScope(a string, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// Add records resource when it references the physical id.
+func (f *sharedResourceFinder) Add(account string, region string, stack cfTypes.StackSummary, resource cfTypes.StackResourceSummary) {
+	if !f.matches(account, region, aws.ToString(resource.ResourceType), aws.ToString(resource.PhysicalResourceId)) {
+		return
+	}
+
+	f.references = append(f.references, sharedResourceReference{
+		Account:            account,
+		Region:             region,
+		StackName:          aws.ToString(stack.StackName),
+		StackStatus:        string(stack.StackStatus),
+		LogicalResourceID:  aws.ToString(resource.LogicalResourceId),
+		ResourceType:       aws.ToString(resource.ResourceType),
+		PhysicalResourceID: aws.ToString(resource.PhysicalResourceId),
+		ResourceStatus:     string(resource.ResourceStatus),
+	})
+}
+
+// Stacks returns the number of distinct stacks referencing the physical id.
+func (f *sharedResourceFinder) Stacks() int {
+	stacks := map[string]bool{}
+	for _, reference := range f.references {
+		stacks[reference.Account+"/"+reference.Region+"/"+reference.StackName] = true
+	}
+
+	return len(stacks)
+}
+
+// printSharedResourceReport logs every stack referencing the physical id, the blast radius of changing it.
+func printSharedResourceReport(finder *sharedResourceFinder) {
+	subject := finder.physicalID
+	if finder.resourceType != "" {
+		subject += " (" + finder.resourceType + ")"
+	}
+
+	log.Printf("Stacks referencing %s: %d (%d resource(s))\n", subject, finder.Stacks(), len(finder.references))
+
+	for _, reference := range finder.references {
+		log.Printf("- %s (%s, %s) %s: %s (%s) %s as %s", reference.StackName, reference.Account, reference.Region,
+			reference.StackStatus, reference.LogicalResourceID, reference.ResourceType, reference.ResourceStatus,
+			reference.PhysicalResourceID)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestSharedResourceReportsEveryStack(t *testing.T) {
+	web := "- web (" + testAccount + ", us-west-2) CREATE_COMPLETE: WebSecurityGroup (AWS::EC2::SecurityGroup)"
+	api := "- api (" + testAccount + ", us-east-1) UPDATE_COMPLETE: SharedSecurityGroup (AWS::EC2::SecurityGroup)"
+
+	tests := []struct {
+		name       string
+		physicalID string
+		references []string
+		lines      []string
+	}{
+		{
+			name:       "id",
+			physicalID: "sg-0123456789abcdef0",
+			references: []string{"us-west-2/web/WebSecurityGroup", "us-east-1/api/SharedSecurityGroup"},
+			lines:      []string{"Stacks referencing sg-0123456789abcdef0: 2 (2 resource(s))\n", web, api},
+		},
+		{
+			// The ARN names us-west-2, so the group of the same id in us-east-1 is another resource.
+			name:       "arn",
+			physicalID: "arn:aws:ec2:us-west-2:" + testAccount + ":security-group/sg-0123456789abcdef0",
+			references: []string{"us-west-2/web/WebSecurityGroup"},
+			lines: []string{
+				"Stacks referencing arn:aws:ec2:us-west-2:" + testAccount + ":security-group/sg-0123456789abcdef0: 1 (1 resource(s))\n",
+				web,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+
+			web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+			api := testStack("us-east-1", "api", cfTypes.StackStatusUpdateComplete)
+			batch := testStack(testDefaultRegion, "batch", cfTypes.StackStatusCreateComplete)
+
+			account := &fakeAccount{
+				regions: []string{testDefaultRegion, "us-east-1"},
+				stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, batch}, "us-east-1": {api}},
+				resources: map[string][]cfTypes.StackResourceSummary{
+					*web.StackId: {
+						testResource("WebSecurityGroup", "AWS::EC2::SecurityGroup", "sg-0123456789abcdef0"),
+						testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+					},
+					*api.StackId:   {testResource("SharedSecurityGroup", "AWS::EC2::SecurityGroup", "sg-0123456789abcdef0")},
+					*batch.StackId: {testResource("BatchSecurityGroup", "AWS::EC2::SecurityGroup", "sg-0fedcba9876543210")},
+				},
+			}
+			s := newTestScanner(t, account, false, "-shared-resource", tt.physicalID, "-concurrency", "1")
+
+			if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+				t.Fatalf("scanAccount error: %v", err)
+			}
+
+			var stacks []string
+			for _, reference := range s.shared.references {
+				stacks = append(stacks, reference.Region+"/"+reference.StackName+"/"+reference.LogicalResourceID)
+			}
+
+			if !slices.Equal(stacks, tt.references) {
+				t.Errorf("references = %v, want %v", stacks, tt.references)
+			}
+
+			printSharedResourceReport(s.shared)
+
+			for _, line := range tt.lines {
+				if !strings.Contains(logs.String(), line) {
+					t.Errorf("log does not contain %q:\n%s", line, logs)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedResourceMatchesNameAndARN(t *testing.T) {
+	const otherAccount = "333333333333"
+
+	roleARN := "arn:aws:iam::" + testAccount + ":role/web-role"
+	queueARN := "arn:aws:sqs:" + testDefaultRegion + ":" + testAccount + ":orders"
+
+	tests := []struct {
+		name         string
+		physicalID   string
+		resourceType string
+		account      string
+		region       string
+		resourceID   string
+		want         bool
+	}{
+		{"same name", "web-role", "AWS::IAM::Role", testAccount, testDefaultRegion, "web-role", true},
+		{"name to own ARN", "web-role", "AWS::IAM::Role", testAccount, testDefaultRegion, roleARN, true},
+		{"name to ARN of another account", "web-role", "AWS::IAM::Role", otherAccount, testDefaultRegion, roleARN, false},
+		{"ARN to name", roleARN, "AWS::IAM::Role", testAccount, "us-east-1", "web-role", true},
+		{"ARN to name in another account", roleARN, "AWS::IAM::Role", otherAccount, testDefaultRegion, "web-role", false},
+		{"ARN to name of another type", roleARN, "AWS::IAM::InstanceProfile", testAccount, testDefaultRegion, "web-role", false},
+		{"regional ARN to name", queueARN, "AWS::SQS::Queue", testAccount, testDefaultRegion, "orders", true},
+		{"regional ARN to name in another region", queueARN, "AWS::SQS::Queue", testAccount, "us-east-1", "orders", false},
+		{"regional ARN to ARN of another account", queueARN, "AWS::SQS::Queue", testAccount, testDefaultRegion,
+			"arn:aws:sqs:" + testDefaultRegion + ":" + otherAccount + ":orders", false},
+		{"bucket ARN to name in any account", "arn:aws:s3:::web-assets", "AWS::S3::Bucket", otherAccount, "us-east-1", "web-assets", true},
+		{"other name", "web-role", "AWS::IAM::Role", testAccount, testDefaultRegion, "api-role", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := newSharedResourceFinder(tt.physicalID, "")
+			if got := finder.matches(tt.account, tt.region, tt.resourceType, tt.resourceID); got != tt.want {
+				t.Errorf("finder of %q matches(%s, %s, %q, %q) = %t, want %t", tt.physicalID, tt.account, tt.region,
+					tt.resourceType, tt.resourceID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedResourceTypeRestrictsName(t *testing.T) {
+	untyped := newSharedResourceFinder("orders", "")
+	typed := newSharedResourceFinder("orders", "AWS::S3::Bucket")
+
+	for _, resourceType := range []string{"AWS::S3::Bucket", "AWS::DynamoDB::Table"} {
+		if !untyped.matches(testAccount, testDefaultRegion, resourceType, "orders") {
+			t.Errorf("the finder without a type does not match the %s named orders", resourceType)
+		}
+	}
+
+	if !typed.matches(testAccount, testDefaultRegion, "AWS::S3::Bucket", "orders") {
+		t.Error("the AWS::S3::Bucket finder does not match the bucket named orders")
+	}
+
+	if typed.matches(testAccount, testDefaultRegion, "AWS::DynamoDB::Table", "orders") {
+		t.Error("the AWS::S3::Bucket finder matches the table named orders")
+	}
+
+	if _, err := parseOptions("scan-stacks", []string{"-shared-resource-type", "AWS::S3::Bucket"}); err == nil {
+		t.Error("parseOptions accepted -shared-resource-type without -shared-resource")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	awsMiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyHTTP "github.com/aws/smithy-go/transport/http"
+)
+
+// responseDate returns the Date header of the raw HTTP response recorded in an operation's result metadata.
+func responseDate(metadata middleware.Metadata) (time.Time, error) {
+	response, ok := awsMiddleware.GetRawResponse(metadata).(*smithyHTTP.Response)
+	if !ok || response == nil {
+		return time.Time{}, fmt.Errorf("no raw HTTP response in result metadata")
+	}
+
+	header := response.Header.Get("Date")
+	if header == "" {
+		return time.Time{}, fmt.Errorf("response has no Date header")
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse Date header '%s': %w", header, err)
+	}
+
+	return date, nil
+}
+
+// clockSkew returns how far local is ahead of (positive) or behind (negative) the AWS server time.
+func clockSkew(metadata middleware.Metadata, local time.Time) (time.Duration, error) {
+	server, err := responseDate(metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	return local.Sub(server), nil
+}
+
+// exceedsSkew reports whether the absolute skew is larger than threshold.
+func exceedsSkew(skew time.Duration, threshold time.Duration) bool {
+	return skew.Abs() > threshold
+}
+
+// checkClockSkew logs the skew between local time and the Date of the response in metadata,
+// warning when it exceeds threshold, and reports whether it did.
+func checkClockSkew(metadata middleware.Metadata, local time.Time, threshold time.Duration) bool {
+	skew, err := clockSkew(metadata, local)
+	if err != nil {
+		log.Printf("Unable to check clock skew: %v", err)
+		return false
+	}
+
+	if exceedsSkew(skew, threshold) {
+		log.Printf("WARNING: local clock is %s off AWS time (threshold %s); request signatures and log windows may be wrong",
+			skew.Round(time.Second), threshold)
+
+		return true
+	}
+
+	log.Printf("Clock skew against AWS: %s\n", skew.Round(time.Second))
+
+	return false
+}
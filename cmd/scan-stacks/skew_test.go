@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// dateHTTPClient answers GetCallerIdentity requests with the given Date header, or none when it is empty.
+type dateHTTPClient struct {
+	date string
+}
+
+func (c dateHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	header := http.Header{"Content-Type": []string{"text/xml"}}
+	if c.date != "" {
+		header.Set("Date", c.date)
+	}
+
+	body := `<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:iam::` + testAccount + `:user/ops</Arn>` +
+		`<UserId>AIDAOPS</UserId><Account>` + testAccount + `</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`
+
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+// callerIdentityMetadata returns the result metadata of a GetCallerIdentity response with the given Date header.
+func callerIdentityMetadata(t *testing.T, date string) middleware.Metadata {
+	t.Helper()
+
+	client := sts.NewFromConfig(aws.Config{Region: testDefaultRegion, Credentials: aws.AnonymousCredentials{}, HTTPClient: dateHTTPClient{date: date}})
+
+	output, err := client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		t.Fatalf("GetCallerIdentity error: %v", err)
+	}
+
+	return output.ResultMetadata
+}
+
+func TestCheckClockSkewThreshold(t *testing.T) {
+	serverDate := testTime.Format(http.TimeFormat)
+
+	tests := []struct {
+		name  string
+		date  string
+		local time.Time
+		warn  bool
+		log   string
+	}{
+		{"within threshold", serverDate, testTime.Add(10 * time.Second), false, "Clock skew against AWS: 10s"},
+		{"ahead past threshold", serverDate, testTime.Add(45 * time.Second), true, "WARNING: local clock is 45s off AWS time (threshold 30s)"},
+		{"behind past threshold", serverDate, testTime.Add(-2 * time.Minute), true, "WARNING: local clock is -2m0s off AWS time (threshold 30s)"},
+		{"no date header", "", testTime, false, "Unable to check clock skew: response has no Date header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+
+			if warned := checkClockSkew(callerIdentityMetadata(t, tt.date), tt.local, 30*time.Second); warned != tt.warn {
+				t.Errorf("checkClockSkew warned %v, want %v", warned, tt.warn)
+			}
+
+			if !strings.Contains(logs.String(), tt.log) {
+				t.Errorf("log does not contain %q:\n%s", tt.log, logs)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// StackSetIDTag is the tag CloudFormation puts on the stacks it deploys for a StackSet. Its value is the
+// StackSet id, <stack set name>:<uuid>.
+const StackSetIDTag = "aws:cloudformation:stackset-id"
+
+// stackSetOrigin returns the name of the StackSet that deployed the stack with the given tags,
+// or nil when the stack was not deployed by a StackSet.
+func stackSetOrigin(tags map[string]string) *string {
+	stackSetID := tags[StackSetIDTag]
+	if stackSetID == "" {
+		return nil
+	}
+
+	name, _, _ := strings.Cut(stackSetID, ":")
+
+	return &name
+}
+
+// cloudFormationStackTags returns the tags of every stack in the region of cfg, by stack id.
+// ListStacks does not return tags, so they come from DescribeStacks.
+func cloudFormationStackTags(ctx context.Context, cfg aws.Config) (map[string]map[string]string, error) {
+	cfClient := cloudformation.NewFromConfig(cfg)
+
+	tags := map[string]map[string]string{}
+
+	paginator := cloudformation.NewDescribeStacksPaginator(cfClient, &cloudformation.DescribeStacksInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe stacks: %w", err)
+		}
+
+		for _, stack := range page.Stacks {
+			stackTags := make(map[string]string, len(stack.Tags))
+			for _, tag := range stack.Tags {
+				stackTags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+
+			tags[aws.ToString(stack.StackId)] = stackTags
+		}
+	}
+
+	return tags, nil
+}
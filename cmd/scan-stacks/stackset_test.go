@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestStackSetOriginFromTag(t *testing.T) {
+	logs := captureLog(t)
+
+	deployed := testStack(testDefaultRegion, "StackSet-ops-baseline-0c1d2e3f-4a5b-6c7d-8e9f-0a1b2c3d4e5f", cfTypes.StackStatusCreateComplete)
+	// A stack named like a StackSet instance, but not deployed by one.
+	lookalike := testStack(testDefaultRegion, "StackSet-copy-0c1d2e3f-4a5b-6c7d-8e9f-0a1b2c3d4e5f", cfTypes.StackStatusCreateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {deployed, lookalike}},
+		tags: map[string]map[string]string{
+			*deployed.StackId: {StackSetIDTag: "ops-baseline:7f9e8d7c-6b5a-4c3d-2e1f-0a9b8c7d6e5f", "team": "platform"},
+		},
+	}
+
+	s := newTestScanner(t, account, false, "-stackset-origin", "-output", "json")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	stacks := s.report.Regions[0].Stacks
+	if len(stacks) != 2 {
+		t.Fatalf("stacks = %+v, want 2", stacks)
+	}
+
+	if got := aws.ToString(stacks[0].StackSetName); got != "ops-baseline" {
+		t.Errorf("StackSetName of the deployed stack = %q, want ops-baseline", got)
+	}
+
+	if stacks[1].StackSetName != nil {
+		t.Errorf("StackSetName of the lookalike stack = %q, want nil", *stacks[1].StackSetName)
+	}
+
+	if stacks[0].Tags != nil {
+		t.Errorf("Tags = %v, want none without -group-by-tag or -policy", stacks[0].Tags)
+	}
+
+	text := newTestScanner(t, account, true, "-stackset-origin")
+	if err := text.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	if want := "  - StackSet: ops-baseline\n"; !strings.Contains(logs.String(), want) {
+		t.Errorf("text output does not contain %q:\n%s", want, logs)
+	}
+}
+
+func TestStackTagsListedOnlyWhenNeeded(t *testing.T) {
+	captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)}},
+	}
+
+	s := newTestScanner(t, account, false, "-output", "json")
+	s.listStackTags = func(context.Context, aws.Config) (map[string]map[string]string, error) {
+		t.Error("listed the stack tags without -stackset-origin, -group-by-tag or -policy")
+		return nil, nil
+	}
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+}
+
+func TestStackTagsErrorFailsRegion(t *testing.T) {
+	logs := captureLog(t)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion, "eu-west-1"},
+		stacks: map[string][]cfTypes.StackSummary{
+			testDefaultRegion: {testStack(testDefaultRegion, "app", cfTypes.StackStatusCreateComplete)},
+			"eu-west-1":       {testStack("eu-west-1", "edge", cfTypes.StackStatusCreateComplete)},
+		},
+		tags: map[string]map[string]string{},
+	}
+
+	s := newTestScanner(t, account, false, "-group-by-tag", "team", "-output", "json", "-concurrency", "1")
+	s.listStackTags = func(ctx context.Context, cfg aws.Config) (map[string]map[string]string, error) {
+		if cfg.Region == "eu-west-1" {
+			return nil, errors.New("Throttling: Rate exceeded")
+		}
+
+		return account.listStackTags(ctx, cfg)
+	}
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	// The stacks of the region without tags must not be grouped as untagged.
+	if len(s.tagGroups) != 1 || s.tagGroups[0].Region != testDefaultRegion {
+		t.Errorf("tag groups = %+v, want only the stack of %s", s.tagGroups, testDefaultRegion)
+	}
+
+	if want := "Error calling cloudFormationStackTags: Throttling: Rate exceeded"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
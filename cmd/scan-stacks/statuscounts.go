@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 8
+	tablePadding  = 2
+)
+
+// stackStatusCounts holds the number of resources of one stack in each resource status.
+type stackStatusCounts struct {
+	Account   string
+	Region    string
+	StackName string
+	Counts    map[string]int
+}
+
+// formatStatusCounts renders counts compactly as STATUS=count pairs sorted by status.
+func formatStatusCounts(counts map[string]int) string {
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+
+	sort.Strings(statuses)
+
+	pairs := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", status, counts[status]))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// writeStatusCountsTable writes one line per stack with its resource counts by status.
+func writeStatusCountsTable(w io.Writer, stacks []stackStatusCounts) error {
+	table := tabwriter.NewWriter(w, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+
+	fmt.Fprintln(table, "ACCOUNT\tREGION\tSTACK\tRESOURCES BY STATUS")
+
+	for _, stack := range stacks {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", stack.Account, stack.Region, stack.StackName, formatStatusCounts(stack.Counts))
+	}
+
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("failed to write status counts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// statusCountsAccount is an account with a stack web whose resources are in various statuses and an empty stack.
+func statusCountsAccount() *fakeAccount {
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusUpdateRollbackComplete)
+	empty := testStack(testDefaultRegion, "empty", cfTypes.StackStatusCreateComplete)
+
+	failed := testResource("Queue", "AWS::SQS::Queue", "jobs")
+	failed.ResourceStatus = cfTypes.ResourceStatusUpdateFailed
+
+	updated := testResource("Role", "AWS::IAM::Role", "web-role")
+	updated.ResourceStatus = cfTypes.ResourceStatusUpdateComplete
+
+	return &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, empty}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				testResource("Topic", "AWS::SNS::Topic", "web-events"),
+				failed,
+				updated,
+			},
+		},
+	}
+}
+
+func TestStatusCountsPerStack(t *testing.T) {
+	captureLog(t)
+
+	want := map[string]int{"CREATE_COMPLETE": 2, "UPDATE_FAILED": 1, "UPDATE_COMPLETE": 1}
+
+	s := newTestScanner(t, statusCountsAccount(), false, "-status-counts", "-output", "json")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	stacks := s.report.Regions[0].Stacks
+	if got := stacks[0].ResourceStatusCounts; !maps.Equal(got, want) {
+		t.Errorf("web counts = %v, want %v", got, want)
+	}
+
+	if got := stacks[1].ResourceStatusCounts; len(got) != 0 {
+		t.Errorf("empty counts = %v, want none", got)
+	}
+
+	text := newTestScanner(t, statusCountsAccount(), false, "-status-counts", "-concurrency", "1")
+
+	if err := text.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeStatusCountsTable(&out, text.statusCounts); err != nil {
+		t.Fatalf("writeStatusCountsTable error: %v", err)
+	}
+
+	wantTable := "ACCOUNT       REGION     STACK  RESOURCES BY STATUS\n" +
+		testAccount + "  us-west-2  web    CREATE_COMPLETE=2 UPDATE_COMPLETE=1 UPDATE_FAILED=1\n" +
+		testAccount + "  us-west-2  empty  \n"
+	if out.String() != wantTable {
+		t.Errorf("status counts table =\n%s\nwant\n%s", out.String(), wantTable)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"sort"
+)
+
+// UntaggedGroup is the group of the stacks without the -group-by-tag tag.
+const UntaggedGroup = "untagged"
+
+// tagGroupStack is a stack in a -group-by-tag group.
+type tagGroupStack struct {
+	Group       string
+	Account     string
+	Region      string
+	StackName   string
+	StackStatus string
+}
+
+// tagGroup returns the value of key in tags, or UntaggedGroup when the tag is missing or empty.
+func tagGroup(tags map[string]string, key string) string {
+	if value := tags[key]; value != "" {
+		return value
+	}
+
+	return UntaggedGroup
+}
+
+// groupStacksByTag groups the stacks by their group, sorted by group name with the untagged group last.
+func groupStacksByTag(stacks []tagGroupStack) ([]string, map[string][]tagGroupStack) {
+	groups := map[string][]tagGroupStack{}
+
+	for _, stack := range stacks {
+		groups[stack.Group] = append(groups[stack.Group], stack)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == UntaggedGroup) != (names[j] == UntaggedGroup) {
+			return names[j] == UntaggedGroup
+		}
+
+		return names[i] < names[j]
+	})
+
+	return names, groups
+}
+
+// printTagGroupReport logs the stacks grouped by the value of the tag key.
+func printTagGroupReport(stacks []tagGroupStack, key string) {
+	names, groups := groupStacksByTag(stacks)
+
+	log.Printf("Stacks by tag '%s': %d stacks in %d groups\n", key, len(stacks), len(names))
+
+	for _, name := range names {
+		log.Printf("- %s: %d stack(s)", name, len(groups[name]))
+
+		for _, stack := range groups[name] {
+			log.Printf("  - %s (%s, %s) %s", stack.StackName, stack.Account, stack.Region, stack.StackStatus)
+		}
+	}
+}
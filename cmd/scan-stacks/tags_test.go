@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestGroupByTagWithUntaggedGroupLast(t *testing.T) {
+	logs := captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	api := testStack(testDefaultRegion, "api", cfTypes.StackStatusUpdateComplete)
+	billing := testStack(testDefaultRegion, "billing", cfTypes.StackStatusCreateComplete)
+	legacy := testStack(testDefaultRegion, "legacy", cfTypes.StackStatusCreateComplete)
+	scratch := testStack(testDefaultRegion, "scratch", cfTypes.StackStatusCreateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web, api, billing, legacy, scratch}},
+		tags: map[string]map[string]string{
+			*web.StackId:     {"team": "platform"},
+			*api.StackId:     {"team": "platform", "env": "prod"},
+			*billing.StackId: {"team": "finance"},
+			// legacy has an empty team tag and scratch has no tags at all.
+			*legacy.StackId: {"team": ""},
+		},
+	}
+	s := newTestScanner(t, account, false, "-group-by-tag", "team", "-concurrency", "1")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	names, groups := groupStacksByTag(s.tagGroups)
+	if want := []string{"finance", "platform", UntaggedGroup}; !slices.Equal(names, want) {
+		t.Fatalf("groups = %v, want %v", names, want)
+	}
+
+	for name, want := range map[string][]string{
+		"finance":     {"billing"},
+		"platform":    {"web", "api"},
+		UntaggedGroup: {"legacy", "scratch"},
+	} {
+		var stacks []string
+		for _, stack := range groups[name] {
+			stacks = append(stacks, stack.StackName)
+		}
+
+		if !slices.Equal(stacks, want) {
+			t.Errorf("group %s = %v, want %v", name, stacks, want)
+		}
+	}
+
+	printTagGroupReport(s.tagGroups, "team")
+
+	for _, line := range []string{
+		"Stacks by tag 'team': 5 stacks in 3 groups\n",
+		"- platform: 2 stack(s)\n  - web (" + testAccount + ", " + testDefaultRegion + ") CREATE_COMPLETE\n",
+		"- untagged: 2 stack(s)\n",
+	} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("log does not contain %q:\n%s", line, logs)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// templateResource is the data a per-resource template is executed with. Field names follow the
+// CloudFormation API; optional fields are pointers and should be printed with the nil-safe funcs.
+type templateResource struct {
+	Account              string
+	Region               string
+	StackId              *string
+	StackName            *string
+	StackStatus          string
+	LogicalResourceId    *string
+	PhysicalResourceId   *string
+	ResourceType         *string
+	ResourceStatus       string
+	ResourceStatusReason *string
+	LastUpdatedTimestamp *time.Time
+}
+
+// resourceTemplate renders one line per stack resource as resources are scanned.
+type resourceTemplate struct {
+	tmpl *template.Template
+	w    io.Writer
+}
+
+// templateFuncs returns the nil-safe functions available to per-resource templates:
+//
+//	str   .Field            the value, or the nil placeholder of the output format
+//	time  .Field            the time in RFC3339, or the nil placeholder
+//	default "text" .Field   the value, or "text" when nil or empty
+func templateFuncs(f formatter) template.FuncMap {
+	return template.FuncMap{
+		"str":  f.String,
+		"time": f.Time,
+		"default": func(fallback string, value *string) string {
+			if value == nil || *value == "" {
+				return fallback
+			}
+
+			return *value
+		},
+	}
+}
+
+// newResourceTemplate parses text as a per-resource template writing to w. A newline is
+// added after each resource unless the template already ends with one.
+func newResourceTemplate(text string, f formatter, w io.Writer) (*resourceTemplate, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("resource").Funcs(templateFuncs(f)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse resource template: %w", err)
+	}
+
+	return &resourceTemplate{tmpl: tmpl, w: w}, nil
+}
+
+// Render writes the template output for one stack resource.
+func (t *resourceTemplate) Render(account string, region string, stack cfTypes.StackSummary, resource cfTypes.StackResourceSummary) error {
+	data := templateResource{
+		Account:              account,
+		Region:               region,
+		StackId:              stack.StackId,
+		StackName:            stack.StackName,
+		StackStatus:          string(stack.StackStatus),
+		LogicalResourceId:    resource.LogicalResourceId,
+		PhysicalResourceId:   resource.PhysicalResourceId,
+		ResourceType:         resource.ResourceType,
+		ResourceStatus:       string(resource.ResourceStatus),
+		ResourceStatusReason: resource.ResourceStatusReason,
+		LastUpdatedTimestamp: resource.LastUpdatedTimestamp,
+	}
+
+	if err := t.tmpl.Execute(t.w, data); err != nil {
+		return fmt.Errorf("failed to render resource template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestResourceTemplatePerLine(t *testing.T) {
+	captureLog(t)
+
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusCreateComplete)
+	updated := testResource("Role", "AWS::IAM::Role", "web-role")
+	updated.LastUpdatedTimestamp = aws.Time(testTime)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web}},
+		resources: map[string][]cfTypes.StackResourceSummary{
+			*web.StackId: {
+				testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+				updated,
+				testResource("Wait", "AWS::CloudFormation::WaitConditionHandle", ""),
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		text string
+		want string
+	}{
+		{
+			name: "fields and str",
+			text: "{{.StackName}} {{.ResourceType}} {{str .PhysicalResourceId}}",
+			want: "web AWS::S3::Bucket web-assets\n" +
+				"web AWS::IAM::Role web-role\n" +
+				"web AWS::CloudFormation::WaitConditionHandle <nil>\n",
+		},
+		{
+			name: "nil placeholder",
+			args: []string{"-nil-placeholder", "-"},
+			text: "{{.Account}}/{{.Region}} {{.LogicalResourceId}} {{time .LastUpdatedTimestamp}}\n",
+			want: testAccount + "/" + testDefaultRegion + " Bucket -\n" +
+				testAccount + "/" + testDefaultRegion + " Role 2025-03-01T12:00:00Z\n" +
+				testAccount + "/" + testDefaultRegion + " Wait -\n",
+		},
+		{
+			name: "default",
+			text: `{{.LogicalResourceId}}={{default "none" .PhysicalResourceId}}`,
+			want: "Bucket=web-assets\nRole=web-role\nWait=none\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScanner(t, account, false, append(tt.args, "-output-template-per-resource", tt.text)...)
+
+			var out bytes.Buffer
+
+			tmpl, err := newResourceTemplate(tt.text, s.format, &out)
+			if err != nil {
+				t.Fatalf("newResourceTemplate error: %v", err)
+			}
+
+			s.template = tmpl
+
+			if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+				t.Fatalf("scanAccount error: %v", err)
+			}
+
+			if out.String() != tt.want {
+				t.Errorf("template output =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceTemplateParseError(t *testing.T) {
+	if _, err := newResourceTemplate("{{.StackName", formatter{}, &bytes.Buffer{}); err == nil {
+		t.Error("newResourceTemplate accepted an unterminated action")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// TerraformManagedMode is the Terraform state resource mode of resources Terraform manages (as opposed to data sources).
+const TerraformManagedMode = "managed"
+
+// terraformState is the subset of the Terraform state file (format version 4) needed to extract resource ids.
+type terraformState struct {
+	Version   int                      `json:"version"`
+	Resources []terraformStateResource `json:"resources"`
+}
+
+// terraformStateResource is a single resource block in a Terraform state file.
+type terraformStateResource struct {
+	Module    string                   `json:"module,omitempty"`
+	Mode      string                   `json:"mode"`
+	Type      string                   `json:"type"`
+	Name      string                   `json:"name"`
+	Instances []terraformStateInstance `json:"instances"`
+}
+
+// terraformStateInstance is one instance of a Terraform state resource.
+type terraformStateInstance struct {
+	Attributes map[string]any `json:"attributes"`
+}
+
+// scannedResource is a CloudFormation stack resource found during a scan.
+type scannedResource struct {
+	Region             string
+	StackName          string
+	LogicalResourceID  string
+	PhysicalResourceID string
+	ResourceType       string
+}
+
+// terraformOverlap is a CloudFormation resource that is also tracked in a Terraform state.
+type terraformOverlap struct {
+	Resource scannedResource
+	Address  string
+}
+
+// terraformCrossReference is the result of comparing scanned CloudFormation resources with a Terraform state.
+type terraformCrossReference struct {
+	// Overlaps are CloudFormation resources that are also tracked in the Terraform state.
+	Overlaps []terraformOverlap
+	// CloudFormationOnly are CloudFormation resources not tracked in the Terraform state.
+	CloudFormationOnly []scannedResource
+	// TerraformOnly are Terraform addresses whose ids were not found in any scanned stack.
+	TerraformOnly []string
+}
+
+// loadTerraformState reads and parses the Terraform state file at path.
+func loadTerraformState(path string) (*terraformState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terraform state: %w", err)
+	}
+
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// address returns the Terraform address of the resource, e.g. module.net.aws_vpc.main.
+func (r terraformStateResource) address() string {
+	address := r.Type + "." + r.Name
+	if r.Module != "" {
+		address = r.Module + "." + address
+	}
+
+	return address
+}
+
+// resourceIDs maps every id and arn attribute of the managed resources in the state to its Terraform address.
+func (s *terraformState) resourceIDs() map[string]string {
+	ids := map[string]string{}
+
+	for _, resource := range s.Resources {
+		if resource.Mode != TerraformManagedMode {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			for _, key := range []string{"id", "arn"} {
+				if value, ok := instance.Attributes[key].(string); ok && value != "" {
+					ids[value] = resource.address()
+				}
+			}
+		}
+	}
+
+	return ids
+}
+
+// crossReferenceTerraformState compares the scanned resources' physical ids against the ids tracked in state.
+// Ids that differ only in form, e.g. a role name in CloudFormation and its ARN in the state, are matched
+// by their normalized id.
+func crossReferenceTerraformState(state *terraformState, resources []scannedResource) terraformCrossReference {
+	ids := state.resourceIDs()
+	matched := map[string]bool{}
+
+	normalizedIDs := map[string]string{}
+
+	for id, address := range ids {
+		if normalized, ok := normalizePhysicalID("", id); ok {
+			normalizedIDs[normalized.String()] = address
+		}
+	}
+
+	result := terraformCrossReference{}
+
+	for _, resource := range resources {
+		address, ok := ids[resource.PhysicalResourceID]
+		if !ok {
+			if normalized, nok := normalizePhysicalID(resource.ResourceType, resource.PhysicalResourceID); nok {
+				address, ok = normalizedIDs[normalized.String()]
+			}
+		}
+
+		if !ok || resource.PhysicalResourceID == "" {
+			result.CloudFormationOnly = append(result.CloudFormationOnly, resource)
+			continue
+		}
+
+		result.Overlaps = append(result.Overlaps, terraformOverlap{Resource: resource, Address: address})
+		matched[address] = true
+	}
+
+	seen := map[string]bool{}
+
+	for _, address := range ids {
+		if !matched[address] && !seen[address] {
+			result.TerraformOnly = append(result.TerraformOnly, address)
+			seen[address] = true
+		}
+	}
+
+	sort.Strings(result.TerraformOnly)
+
+	return result
+}
+
+// printTerraformCrossReport logs the overlaps and gaps between CloudFormation and Terraform.
+func printTerraformCrossReport(result terraformCrossReference) {
+	log.Printf("Resources tracked by both CloudFormation and Terraform: %d\n", len(result.Overlaps))
+
+	for _, overlap := range result.Overlaps {
+		resource := overlap.Resource
+		log.Printf("- %s (%s/%s) -> %s", resource.PhysicalResourceID, resource.StackName, resource.LogicalResourceID, overlap.Address)
+	}
+
+	log.Printf("Resources only in CloudFormation: %d\n", len(result.CloudFormationOnly))
+
+	for _, resource := range result.CloudFormationOnly {
+		log.Printf("- %s (%s/%s, %s, %s)", resource.PhysicalResourceID, resource.StackName, resource.LogicalResourceID,
+			resource.ResourceType, resource.Region)
+	}
+
+	log.Printf("Resources only in Terraform state: %d\n", len(result.TerraformOnly))
+
+	for _, address := range result.TerraformOnly {
+		log.Printf("- %s", address)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCrossReferenceTerraformState(t *testing.T) {
+	state, err := loadTerraformState("testdata/terraform.tfstate")
+	if err != nil {
+		t.Fatalf("loadTerraformState error: %v", err)
+	}
+
+	resources := []scannedResource{
+		{Region: testDefaultRegion, StackName: "app", LogicalResourceID: "Logs", PhysicalResourceID: "app-logs-bucket", ResourceType: "AWS::S3::Bucket"},
+		{Region: testDefaultRegion, StackName: "app", LogicalResourceID: "Role", PhysicalResourceID: "deploy", ResourceType: "AWS::IAM::Role"},
+		{Region: testDefaultRegion, StackName: "app", LogicalResourceID: "Vpc", PhysicalResourceID: "vpc-0123456789abcdef0", ResourceType: "AWS::EC2::VPC"},
+		{Region: testDefaultRegion, StackName: "app", LogicalResourceID: "Pending", ResourceType: "AWS::SNS::Topic"},
+	}
+
+	result := crossReferenceTerraformState(state, resources)
+
+	var overlaps []string
+	for _, overlap := range result.Overlaps {
+		overlaps = append(overlaps, overlap.Resource.LogicalResourceID+"="+overlap.Address)
+	}
+
+	if want := []string{"Logs=aws_s3_bucket.logs", "Role=module.iam.aws_iam_role.deploy"}; !slices.Equal(overlaps, want) {
+		t.Errorf("overlaps = %v, want %v", overlaps, want)
+	}
+
+	var cloudFormationOnly []string
+	for _, resource := range result.CloudFormationOnly {
+		cloudFormationOnly = append(cloudFormationOnly, resource.LogicalResourceID)
+	}
+
+	// Data sources are not managed by Terraform, so the VPC only belongs to CloudFormation.
+	if want := []string{"Vpc", "Pending"}; !slices.Equal(cloudFormationOnly, want) {
+		t.Errorf("CloudFormation only = %v, want %v", cloudFormationOnly, want)
+	}
+
+	if want := []string{"aws_sqs_queue.orphan"}; !slices.Equal(result.TerraformOnly, want) {
+		t.Errorf("Terraform only = %v, want %v", result.TerraformOnly, want)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// DefaultMinRPS is the default lowest request rate the adaptive throttle backs off to.
+	DefaultMinRPS = 1.0
+	// ThrottleBackoffFactor divides the request rate after each throttling error.
+	ThrottleBackoffFactor = 2.0
+	// ThrottleRecoveryStep is added to the request rate, in requests per second, after each successful request.
+	ThrottleRecoveryStep = 0.5
+)
+
+// throttlingErrorCodes are the API error codes AWS services use to signal request throttling.
+var throttlingErrorCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestLimitExceeded":                   true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+}
+
+// isThrottlingError reports whether err is an AWS API throttling error.
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return throttlingErrorCodes[apiErr.ErrorCode()]
+	}
+
+	return false
+}
+
+// adaptiveThrottle limits the request rate, halving it when requests are throttled and
+// raising it step by step while they succeed, always within [minRPS, maxRPS].
+// A nil throttle does not limit anything.
+type adaptiveThrottle struct {
+	mu     sync.Mutex
+	minRPS float64
+	maxRPS float64
+	rate   float64
+	next   time.Time
+}
+
+// newAdaptiveThrottle returns a throttle starting at maxRPS.
+func newAdaptiveThrottle(minRPS float64, maxRPS float64) (*adaptiveThrottle, error) {
+	if minRPS <= 0 || maxRPS < minRPS {
+		return nil, fmt.Errorf("invalid throttle range: min %.2f, max %.2f requests per second", minRPS, maxRPS)
+	}
+
+	return &adaptiveThrottle{minRPS: minRPS, maxRPS: maxRPS, rate: maxRPS}, nil
+}
+
+// Rate returns the current request rate in requests per second.
+func (t *adaptiveThrottle) Rate() float64 {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rate
+}
+
+// Wait blocks until the next request may be sent at the current rate.
+func (t *adaptiveThrottle) Wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(time.Duration(float64(time.Second) / t.rate))
+	t.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("throttle wait cancelled: %w", ctx.Err())
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// Observe adapts the rate to the outcome of a request: throttling errors back off, successes recover.
+// Other errors leave the rate unchanged.
+func (t *adaptiveThrottle) Observe(err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case err == nil:
+		t.rate = min(t.maxRPS, t.rate+ThrottleRecoveryStep)
+	case isThrottlingError(err):
+		t.rate = max(t.minRPS, t.rate/ThrottleBackoffFactor)
+	}
+}
+
+// throttledRetryer paces every attempt of an SDK client with throttle, including the retries of the SDK itself,
+// so the throttle observes each throttling error before the SDK retries the request.
+type throttledRetryer struct {
+	aws.Retryer
+	throttle *adaptiveThrottle
+}
+
+// newThrottledRetryer wraps retryer so that each attempt waits for and reports to throttle.
+func newThrottledRetryer(retryer aws.Retryer, throttle *adaptiveThrottle) aws.RetryerV2 {
+	return &throttledRetryer{Retryer: retryer, throttle: throttle}
+}
+
+// GetAttemptToken waits for the throttle before an attempt; the returned release func reports the attempt's outcome.
+func (r *throttledRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	if err := r.throttle.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var release func(error) error
+	var err error
+
+	if v2, ok := r.Retryer.(aws.RetryerV2); ok {
+		release, err = v2.GetAttemptToken(ctx)
+	} else {
+		release, err = r.Retryer.GetInitialToken(), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt token: %w", err)
+	}
+
+	return func(attemptErr error) error {
+		r.throttle.Observe(attemptErr)
+
+		return release(attemptErr)
+	}, nil
+}
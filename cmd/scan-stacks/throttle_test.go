@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+const (
+	throttlingResponse = `<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code>` +
+		`<Message>Rate exceeded</Message></Error><RequestId>throttled</RequestId></ErrorResponse>`
+	listStackResourcesResponse = `<ListStackResourcesResponse><ListStackResourcesResult><StackResourceSummaries>` +
+		`<member><LogicalResourceId>Bucket</LogicalResourceId><ResourceType>AWS::S3::Bucket</ResourceType></member>` +
+		`</StackResourceSummaries></ListStackResourcesResult></ListStackResourcesResponse>`
+)
+
+// alternatingThrottleHTTPClient answers every other CloudFormation request with a throttling error, starting with one.
+type alternatingThrottleHTTPClient struct {
+	requests int
+}
+
+func (c *alternatingThrottleHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+
+	status, body := http.StatusOK, listStackResourcesResponse
+	if c.requests%2 == 1 {
+		status, body = http.StatusBadRequest, throttlingResponse
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestThrottleObservesEveryAttemptOfTheSDKRetryer(t *testing.T) {
+	client := &alternatingThrottleHTTPClient{}
+	cfg := aws.Config{
+		Region:      testDefaultRegion,
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  client,
+		Retryer: func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.RateLimiter = ratelimit.None
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			})
+		},
+	}
+
+	throttle, err := newAdaptiveThrottle(1, 100)
+	if err != nil {
+		t.Fatalf("newAdaptiveThrottle error: %v", err)
+	}
+
+	// Each call is throttled once and succeeds when the SDK retries it.
+	want := 100.0
+	for call := range 3 {
+		resources, lerr := cloudFormationListStackResources(context.Background(), cfg, "stack", throttle, nil)
+		if lerr != nil {
+			t.Fatalf("call %d: cloudFormationListStackResources error: %v", call, lerr)
+		}
+
+		if len(*resources) != 1 || aws.ToString((*resources)[0].LogicalResourceId) != "Bucket" {
+			t.Fatalf("call %d: resources = %+v, want Bucket", call, *resources)
+		}
+
+		want = min(100, want/ThrottleBackoffFactor+ThrottleRecoveryStep)
+		if got := throttle.Rate(); got != want {
+			t.Errorf("call %d: rate = %.3f, want %.3f", call, got, want)
+		}
+	}
+
+	if client.requests != 6 {
+		t.Errorf("HTTP requests = %d, want 6", client.requests)
+	}
+}
+
+func TestThrottlePerRegion(t *testing.T) {
+	captureLog(t)
+
+	regions := []string{testDefaultRegion, "us-east-1"}
+	account := &fakeAccount{regions: regions, stacks: map[string][]cfTypes.StackSummary{}}
+
+	for _, region := range regions {
+		account.stacks[region] = []cfTypes.StackSummary{
+			testStack(region, "web", cfTypes.StackStatusCreateComplete),
+			testStack(region, "queue", cfTypes.StackStatusCreateComplete),
+		}
+	}
+
+	s := newTestScanner(t, account, false, "-max-rps", "50")
+
+	if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+		t.Fatalf("scanAccount error: %v", err)
+	}
+
+	west, east := account.throttles[testDefaultRegion], account.throttles["us-east-1"]
+	if len(west) != 2 || len(east) != 2 {
+		t.Fatalf("throttles = %v, want two listings per region", account.throttles)
+	}
+
+	if west[0] == nil || west[0] != west[1] || east[0] != east[1] || west[0] == east[0] {
+		t.Errorf("throttles = %v, want one throttle per region", account.throttles)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// resourceTransition is one status change of a resource.
+type resourceTransition struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// resourceTimeline is the status history of a resource, reconstructed from its stack's events.
+type resourceTimeline struct {
+	// Created is when the current resource finished creating, nil when it is older than the event history.
+	Created *time.Time `json:"created"`
+	// LastUpdated is when the resource last finished updating, nil when it was never updated.
+	LastUpdated *time.Time `json:"lastUpdated"`
+	// Updates is the number of completed updates.
+	Updates     int                  `json:"updates"`
+	Transitions []resourceTransition `json:"transitions"`
+}
+
+// buildResourceTimelines reconstructs the timeline of every resource, by logical id, from the events
+// of a stack, most recent first as returned by DescribeStackEvents.
+func buildResourceTimelines(events []cfTypes.StackEvent) map[string]*resourceTimeline {
+	timelines := map[string]*resourceTimeline{}
+
+	for _, event := range slices.Backward(events) {
+		if event.LogicalResourceId == nil || event.Timestamp == nil {
+			continue
+		}
+
+		timeline, ok := timelines[*event.LogicalResourceId]
+		if !ok {
+			timeline = &resourceTimeline{Transitions: []resourceTransition{}}
+			timelines[*event.LogicalResourceId] = timeline
+		}
+
+		timestamp := *event.Timestamp
+
+		timeline.Transitions = append(timeline.Transitions, resourceTransition{
+			Status:    string(event.ResourceStatus),
+			Timestamp: timestamp,
+		})
+
+		if event.ResourceStatus == cfTypes.ResourceStatusCreateComplete {
+			// A replaced resource is created again; the latest creation is the current resource.
+			timeline.Created = &timestamp
+		} else if event.ResourceStatus == cfTypes.ResourceStatusUpdateComplete {
+			timeline.LastUpdated = &timestamp
+			timeline.Updates++
+		}
+	}
+
+	return timelines
+}
+
+// logResourceTimeline logs the creation and update times of a resource in text output.
+func (f formatter) logResourceTimeline(timeline *resourceTimeline) {
+	if timeline == nil {
+		return
+	}
+
+	log.Printf("     - Created: %s", f.Time(timeline.Created))
+	log.Printf("     - Last Updated: %s (%d update(s))", f.Time(timeline.LastUpdated), timeline.Updates)
+}
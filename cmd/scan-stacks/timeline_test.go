@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// timelineEvents are the events of stack "web": Bucket was created and updated twice, Role was replaced.
+func timelineEvents() []cfTypes.StackEvent {
+	const (
+		bucketType = "AWS::S3::Bucket"
+		roleType   = "AWS::IAM::Role"
+	)
+
+	return []cfTypes.StackEvent{
+		testStackEvent("web", 30, "Bucket", bucketType, cfTypes.ResourceStatusUpdateComplete, ""),
+		testStackEvent("web", 29, "Bucket", bucketType, cfTypes.ResourceStatusUpdateInProgress, ""),
+		testStackEvent("web", 20, "Bucket", bucketType, cfTypes.ResourceStatusUpdateComplete, ""),
+		testStackEvent("web", 19, "Bucket", bucketType, cfTypes.ResourceStatusUpdateInProgress, ""),
+		testStackEvent("web", 15, "Role", roleType, cfTypes.ResourceStatusCreateComplete, ""),
+		testStackEvent("web", 14, "Role", roleType, cfTypes.ResourceStatusCreateInProgress, "Requested update requires the creation of a new physical resource"),
+		testStackEvent("web", 3, "Role", roleType, cfTypes.ResourceStatusCreateComplete, ""),
+		testStackEvent("web", 2, "Bucket", bucketType, cfTypes.ResourceStatusCreateComplete, ""),
+		testStackEvent("web", 1, "Role", roleType, cfTypes.ResourceStatusCreateInProgress, ""),
+		testStackEvent("web", 1, "Bucket", bucketType, cfTypes.ResourceStatusCreateInProgress, ""),
+	}
+}
+
+// minuteOf returns minute of testTime, as testStackEvent sets it.
+func minuteOf(minute int) time.Time {
+	return testTime.Add(time.Duration(minute) * time.Minute)
+}
+
+func TestBuildResourceTimelines(t *testing.T) {
+	timelines := buildResourceTimelines(timelineEvents())
+
+	bucket := timelines["Bucket"]
+	if bucket == nil || !aws.ToTime(bucket.Created).Equal(minuteOf(2)) || !aws.ToTime(bucket.LastUpdated).Equal(minuteOf(30)) ||
+		bucket.Updates != 2 {
+		t.Errorf("Bucket timeline = %+v, want created at minute 2 and updated twice, last at minute 30", bucket)
+	}
+
+	var statuses []string
+	for _, transition := range bucket.Transitions {
+		statuses = append(statuses, transition.Status)
+	}
+
+	want := []string{"CREATE_IN_PROGRESS", "CREATE_COMPLETE", "UPDATE_IN_PROGRESS", "UPDATE_COMPLETE", "UPDATE_IN_PROGRESS", "UPDATE_COMPLETE"}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("Bucket transitions = %v, want oldest first %v", statuses, want)
+	}
+
+	role := timelines["Role"]
+	if role == nil || !aws.ToTime(role.Created).Equal(minuteOf(15)) || role.LastUpdated != nil || role.Updates != 0 {
+		t.Errorf("Role timeline = %+v, want created by its replacement at minute 15 and never updated", role)
+	}
+}
+
+func TestResourceTimelineInReports(t *testing.T) {
+	web := testStack(testDefaultRegion, "web", cfTypes.StackStatusUpdateComplete)
+
+	account := &fakeAccount{
+		regions: []string{testDefaultRegion},
+		stacks:  map[string][]cfTypes.StackSummary{testDefaultRegion: {web}},
+		resources: map[string][]cfTypes.StackResourceSummary{*web.StackId: {
+			testResource("Bucket", "AWS::S3::Bucket", "web-assets"),
+			testResource("Role", "AWS::IAM::Role", "web-role"),
+			// Queue predates the event history.
+			testResource("Queue", "AWS::SQS::Queue", "jobs"),
+		}},
+		events: map[string][]cfTypes.StackEvent{*web.StackId: timelineEvents()},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		captureLog(t)
+
+		s := newTestScanner(t, account, false, "-resource-timeline", "-output", "json")
+
+		if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+			t.Fatalf("scanAccount error: %v", err)
+		}
+
+		resources := s.report.Regions[0].Stacks[0].Resources
+		if len(resources) != 3 {
+			t.Fatalf("resources = %+v, want 3", resources)
+		}
+
+		if timeline := resources[0].Timeline; timeline == nil || timeline.Updates != 2 {
+			t.Errorf("Bucket timeline = %+v, want 2 updates", timeline)
+		}
+
+		if timeline := resources[1].Timeline; timeline == nil || !aws.ToTime(timeline.Created).Equal(minuteOf(15)) {
+			t.Errorf("Role timeline = %+v, want created at minute 15", timeline)
+		}
+
+		if resources[2].Timeline != nil {
+			t.Errorf("Queue timeline = %+v, want none", resources[2].Timeline)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		logs := captureLog(t)
+
+		s := newTestScanner(t, account, true, "-resource-timeline")
+
+		if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+			t.Fatalf("scanAccount error: %v", err)
+		}
+
+		for _, want := range []string{
+			"     - Created: 2025-03-01T12:02:00Z\n     - Last Updated: 2025-03-01T12:30:00Z (2 update(s))\n",
+			"     - Created: 2025-03-01T12:15:00Z\n     - Last Updated: <nil> (0 update(s))\n",
+		} {
+			if !strings.Contains(logs.String(), want) {
+				t.Errorf("text output does not contain %q:\n%s", want, logs)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		captureLog(t)
+
+		s := newTestScanner(t, account, false, "-output", "json")
+
+		if err := s.scanAccount(context.Background(), scanTarget{AccountID: testAccount}, testDefaultRegion); err != nil {
+			t.Fatalf("scanAccount error: %v", err)
+		}
+
+		if timeline := s.report.Regions[0].Stacks[0].Resources[0].Timeline; timeline != nil {
+			t.Errorf("Bucket timeline without -resource-timeline = %+v, want none", timeline)
+		}
+	})
+}
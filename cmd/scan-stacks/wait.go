@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+const (
+	// DefaultWaitInterval is the default delay between DescribeStacks polls in -wait-for-stable mode.
+	DefaultWaitInterval = 15 * time.Second
+	// ExitCodeStackFailed is the exit code used when a watched stack settles in a failed or rolled back state.
+	ExitCodeStackFailed = 2
+)
+
+// stackStatusFunc returns the current status of the stack being watched.
+type stackStatusFunc func(ctx context.Context) (cfTypes.StackStatus, error)
+
+// stackDescribeFunc describes a single stack by name or id.
+type stackDescribeFunc func(ctx context.Context, stackName string) (*cfTypes.Stack, error)
+
+// pollStackStatus returns a stackStatusFunc describing the stack stackName. The first poll resolves the
+// stack id and the following ones describe the stack by id, as a deleted stack can no longer be described by name.
+func pollStackStatus(describe stackDescribeFunc, stackName string) stackStatusFunc {
+	stackID := stackName
+
+	return func(ctx context.Context) (cfTypes.StackStatus, error) {
+		stack, err := describe(ctx, stackID)
+		if err != nil {
+			return "", err
+		}
+
+		if stack.StackId != nil {
+			stackID = *stack.StackId
+		}
+
+		return stack.StackStatus, nil
+	}
+}
+
+// isStableStackStatus reports whether status is terminal, i.e. no operation is in progress.
+func isStableStackStatus(status cfTypes.StackStatus) bool {
+	return !strings.HasSuffix(string(status), "_IN_PROGRESS")
+}
+
+// isSuccessfulStackStatus reports whether status is a terminal state of an operation that succeeded.
+func isSuccessfulStackStatus(status cfTypes.StackStatus) bool {
+	successful := []cfTypes.StackStatus{
+		cfTypes.StackStatusCreateComplete,
+		cfTypes.StackStatusUpdateComplete,
+		cfTypes.StackStatusImportComplete,
+		cfTypes.StackStatusDeleteComplete,
+	}
+
+	return slices.Contains(successful, status)
+}
+
+// stableExitCode maps the terminal status of a watched stack to the process exit code.
+func stableExitCode(status cfTypes.StackStatus) int {
+	if isSuccessfulStackStatus(status) {
+		return 0
+	}
+
+	return ExitCodeStackFailed
+}
+
+// waitForStableStack polls getStatus every interval until the stack is stable, logging each status transition,
+// and returns the terminal status. A stack in REVIEW_IN_PROGRESS is polled until its change set is executed,
+// so a positive timeout bounds the wait.
+func waitForStableStack(ctx context.Context, getStatus stackStatusFunc, interval time.Duration,
+	timeout time.Duration,
+) (cfTypes.StackStatus, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var previous cfTypes.StackStatus
+
+	for {
+		status, err := getStatus(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get stack status: %w", err)
+		}
+
+		if status != previous {
+			if previous == "" {
+				log.Printf("Stack status: %s", status)
+			} else {
+				log.Printf("Stack status: %s -> %s", previous, status)
+			}
+
+			previous = status
+		}
+
+		if isStableStackStatus(status) {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("stopped waiting for stack: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
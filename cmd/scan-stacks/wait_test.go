@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+const testStackID = "arn:aws:cloudformation:us-west-2:111111111111:stack/app/id"
+
+// fakeStackProgression describes the stack "app" with the next status of statuses on every call. Like
+// CloudFormation, a stack in DELETE_COMPLETE can only be described by id.
+type fakeStackProgression struct {
+	statuses []cfTypes.StackStatus
+	names    []string
+}
+
+func (f *fakeStackProgression) describe(_ context.Context, stackName string) (*cfTypes.Stack, error) {
+	f.names = append(f.names, stackName)
+
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+
+	if stackName != testStackID && (stackName != "app" || status == cfTypes.StackStatusDeleteComplete) {
+		return nil, fmt.Errorf("stack with id %s does not exist", stackName)
+	}
+
+	return &cfTypes.Stack{StackId: aws.String(testStackID), StackName: aws.String("app"), StackStatus: status}, nil
+}
+
+func TestWaitForStableStackProgression(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []cfTypes.StackStatus
+		transitions []string
+		exitCode    int
+	}{
+		{
+			name: "update rolled back",
+			statuses: []cfTypes.StackStatus{
+				cfTypes.StackStatusUpdateInProgress,
+				cfTypes.StackStatusUpdateInProgress,
+				cfTypes.StackStatusUpdateRollbackInProgress,
+				cfTypes.StackStatusUpdateRollbackCompleteCleanupInProgress,
+				cfTypes.StackStatusUpdateRollbackComplete,
+			},
+			transitions: []string{
+				"Stack status: UPDATE_IN_PROGRESS",
+				"Stack status: UPDATE_IN_PROGRESS -> UPDATE_ROLLBACK_IN_PROGRESS",
+				"Stack status: UPDATE_ROLLBACK_IN_PROGRESS -> UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS",
+				"Stack status: UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS -> UPDATE_ROLLBACK_COMPLETE",
+			},
+			exitCode: ExitCodeStackFailed,
+		},
+		{
+			name:     "create succeeded",
+			statuses: []cfTypes.StackStatus{cfTypes.StackStatusCreateInProgress, cfTypes.StackStatusCreateComplete},
+			transitions: []string{
+				"Stack status: CREATE_IN_PROGRESS",
+				"Stack status: CREATE_IN_PROGRESS -> CREATE_COMPLETE",
+			},
+			exitCode: 0,
+		},
+		{
+			name:     "deleted",
+			statuses: []cfTypes.StackStatus{cfTypes.StackStatusDeleteInProgress, cfTypes.StackStatusDeleteComplete},
+			transitions: []string{
+				"Stack status: DELETE_IN_PROGRESS",
+				"Stack status: DELETE_IN_PROGRESS -> DELETE_COMPLETE",
+			},
+			exitCode: 0,
+		},
+		{
+			name: "change set executed",
+			statuses: []cfTypes.StackStatus{
+				cfTypes.StackStatusReviewInProgress,
+				cfTypes.StackStatusReviewInProgress,
+				cfTypes.StackStatusCreateInProgress,
+				cfTypes.StackStatusCreateComplete,
+			},
+			transitions: []string{
+				"Stack status: REVIEW_IN_PROGRESS",
+				"Stack status: REVIEW_IN_PROGRESS -> CREATE_IN_PROGRESS",
+				"Stack status: CREATE_IN_PROGRESS -> CREATE_COMPLETE",
+			},
+			exitCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+			progression := &fakeStackProgression{statuses: tt.statuses}
+
+			status, err := waitForStableStack(context.Background(), pollStackStatus(progression.describe, "app"), time.Millisecond, 0)
+			if err != nil {
+				t.Fatalf("waitForStableStack error: %v", err)
+			}
+
+			if got := strings.Split(strings.TrimSpace(logs.String()), "\n"); strings.Join(got, "|") != strings.Join(tt.transitions, "|") {
+				t.Errorf("transitions = %q, want %q", got, tt.transitions)
+			}
+
+			if got := stableExitCode(status); got != tt.exitCode {
+				t.Errorf("exit code for %s = %d, want %d", status, got, tt.exitCode)
+			}
+
+			if progression.names[0] != "app" || (len(progression.names) > 1 && progression.names[1] != testStackID) {
+				t.Errorf("described stacks = %v, want app then %s", progression.names, testStackID)
+			}
+		})
+	}
+}
+
+func TestWaitForStableStackStopsOnError(t *testing.T) {
+	captureLog(t)
+
+	_, err := waitForStableStack(context.Background(), func(context.Context) (cfTypes.StackStatus, error) {
+		return "", fmt.Errorf("stack with id app does not exist")
+	}, time.Millisecond, 0)
+	if err == nil {
+		t.Error("waitForStableStack returned no error for a missing stack")
+	}
+}
+
+func TestWaitForStableStackTimesOutInReview(t *testing.T) {
+	captureLog(t)
+
+	progression := &fakeStackProgression{statuses: []cfTypes.StackStatus{cfTypes.StackStatusReviewInProgress}}
+
+	_, err := waitForStableStack(context.Background(), pollStackStatus(progression.describe, "app"), time.Millisecond,
+		20*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waitForStableStack error = %v, want the -wait-timeout deadline", err)
+	}
+}
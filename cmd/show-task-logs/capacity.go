@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	// CapacityProviderAttachmentType is the cluster attachment type of the scaling policy of an
+	// Auto Scaling group capacity provider.
+	CapacityProviderAttachmentType = "as_policy"
+	// CapacityProviderNameDetail is the attachment detail naming the capacity provider.
+	CapacityProviderNameDetail = "capacityProviderName"
+)
+
+// describeCluster returns the cluster with its attachments, which carry the status of its capacity providers.
+func describeCluster(ctx context.Context, ecsClient ecsAPI, cluster string) (*ecsTypes.Cluster, error) {
+	resp, err := ecsClient.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+		Clusters: []string{cluster},
+		Include:  []ecsTypes.ClusterField{ecsTypes.ClusterFieldAttachments},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe clusters: %w", err)
+	}
+
+	if len(resp.Clusters) == 0 {
+		return nil, fmt.Errorf("cluster not found")
+	}
+
+	return &resp.Clusters[0], nil
+}
+
+// capacityProviderLines returns the capacity provider context of task in cluster; cluster may be nil
+// when it could not be described.
+func capacityProviderLines(task ecsTypes.Task, cluster *ecsTypes.Cluster) []string {
+	name := aws.ToString(task.CapacityProviderName)
+	if name == "" {
+		return []string{fmt.Sprintf("Capacity Provider: none (launch type %s)", task.LaunchType)}
+	}
+
+	lines := []string{fmt.Sprintf("Capacity Provider: %s", name)}
+
+	if cluster == nil {
+		return lines
+	}
+
+	if !slices.Contains(cluster.CapacityProviders, name) {
+		return append(lines, "Capacity Provider Status: no longer associated with the cluster")
+	}
+
+	for _, item := range cluster.DefaultCapacityProviderStrategy {
+		if aws.ToString(item.CapacityProvider) == name {
+			lines = append(lines, fmt.Sprintf("Capacity Provider Strategy: weight %d, base %d", item.Weight, item.Base))
+		}
+	}
+
+	for _, attachment := range cluster.Attachments {
+		if aws.ToString(attachment.Type) == CapacityProviderAttachmentType &&
+			attachmentDetail(attachment, CapacityProviderNameDetail) == name {
+			lines = append(lines, fmt.Sprintf("Capacity Provider Status: %s", aws.ToString(attachment.Status)))
+		}
+	}
+
+	if cluster.AttachmentsStatus != nil {
+		lines = append(lines, fmt.Sprintf("Cluster Capacity Providers Update: %s", aws.ToString(cluster.AttachmentsStatus)))
+	}
+
+	return lines
+}
+
+// printTaskCapacityProvider writes the capacity provider that placed task and its status in cluster.
+func printTaskCapacityProvider(w io.Writer, task ecsTypes.Task, cluster *ecsTypes.Cluster) error {
+	for _, line := range capacityProviderLines(task, cluster) {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write capacity provider details: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// testCapacityCluster returns cluster prod, whose default strategy spreads tasks over the spot and on-demand
+// capacity providers, with the scaling policy of spot attached.
+func testCapacityCluster() ecsTypes.Cluster {
+	return ecsTypes.Cluster{
+		ClusterName:       aws.String("prod"),
+		CapacityProviders: []string{"spot", "on-demand"},
+		DefaultCapacityProviderStrategy: []ecsTypes.CapacityProviderStrategyItem{
+			{CapacityProvider: aws.String("spot"), Weight: 3, Base: 0},
+			{CapacityProvider: aws.String("on-demand"), Weight: 1, Base: 2},
+		},
+		Attachments: []ecsTypes.Attachment{{
+			Type:    aws.String(CapacityProviderAttachmentType),
+			Status:  aws.String("CREATED"),
+			Details: []ecsTypes.KeyValuePair{{Name: aws.String(CapacityProviderNameDetail), Value: aws.String("spot")}},
+		}},
+		AttachmentsStatus: aws.String("UPDATE_COMPLETE"),
+	}
+}
+
+// capacityDetails returns the capacity provider details printTaskCapacityProvider writes for task in cluster.
+func capacityDetails(t *testing.T, task ecsTypes.Task, cluster *ecsTypes.Cluster) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := printTaskCapacityProvider(&buf, task, cluster); err != nil {
+		t.Fatalf("printTaskCapacityProvider error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestPrintTaskCapacityProvider(t *testing.T) {
+	cluster := testCapacityCluster()
+
+	tests := []struct {
+		name     string
+		provider string
+		cluster  *ecsTypes.Cluster
+		want     []string
+	}{
+		{
+			name:     "associated",
+			provider: "spot",
+			cluster:  &cluster,
+			want: []string{
+				"Capacity Provider: spot\n",
+				"Capacity Provider Strategy: weight 3, base 0\n",
+				"Capacity Provider Status: CREATED\n",
+				"Cluster Capacity Providers Update: UPDATE_COMPLETE\n",
+			},
+		},
+		{
+			name:     "removed from the cluster",
+			provider: "legacy",
+			cluster:  &cluster,
+			want:     []string{"Capacity Provider: legacy\nCapacity Provider Status: no longer associated with the cluster\n"},
+		},
+		{
+			name:     "cluster not described",
+			provider: "spot",
+			want:     []string{"Capacity Provider: spot\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := testTask("prod", "abc", "web")
+			task.CapacityProviderName = aws.String(tt.provider)
+
+			output := capacityDetails(t, task, tt.cluster)
+
+			for _, line := range tt.want {
+				if !strings.Contains(output, line) {
+					t.Errorf("output is missing %q:\n%s", line, output)
+				}
+			}
+
+			if tt.cluster == nil && strings.Contains(output, "Capacity Provider Status") {
+				t.Errorf("output has a capacity provider status without the cluster:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestPrintTaskCapacityProviderWithoutCapacityProvider(t *testing.T) {
+	task := testTask("prod", "abc", "web")
+	task.LaunchType = ecsTypes.LaunchTypeFargate
+
+	cluster := testCapacityCluster()
+	output := capacityDetails(t, task, &cluster)
+
+	if want := "Capacity Provider: none (launch type FARGATE)\n"; !strings.Contains(output, want) {
+		t.Errorf("output is missing %q:\n%s", want, output)
+	}
+
+	if strings.Contains(output, "Strategy") {
+		t.Errorf("output has the strategy of another capacity provider:\n%s", output)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// parseClusters splits a -clusters value of the form cluster[,cluster...].
+func parseClusters(value string) ([]string, error) {
+	clusters := strings.Split(value, ",")
+
+	for _, cluster := range clusters {
+		if cluster == "" {
+			return nil, fmt.Errorf("-clusters has an empty cluster: %s", value)
+		}
+	}
+
+	return clusters, nil
+}
+
+// prefixWriter prefixes every line written to w, so the output of several clusters can be told apart.
+type prefixWriter struct {
+	w       io.Writer
+	prefix  string
+	midLine bool
+}
+
+// newClusterWriter returns a writer prefixing the lines written to w with the cluster name.
+func newClusterWriter(w io.Writer, cluster string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: fmt.Sprintf("[%s] ", cluster)}
+}
+
+// Write writes p to the underlying writer, starting each line with the prefix.
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	written := len(p)
+
+	for len(p) > 0 {
+		if !pw.midLine {
+			if _, err := io.WriteString(pw.w, pw.prefix); err != nil {
+				return 0, err
+			}
+		}
+
+		line := p
+		if end := bytes.IndexByte(p, '\n'); end >= 0 {
+			line = p[:end+1]
+		}
+
+		if _, err := pw.w.Write(line); err != nil {
+			return 0, err
+		}
+
+		pw.midLine = line[len(line)-1] != '\n'
+		p = p[len(line):]
+	}
+
+	return written, nil
+}
+
+// listServiceTasks returns the ARNs of the running tasks of service in cluster.
+func listServiceTasks(ctx context.Context, ecsClient ecsAPI, cluster string, service string) ([]string, error) {
+	var taskARNs []string
+
+	paginator := ecs.NewListTasksPaginator(ecsClient, &ecs.ListTasksInput{
+		Cluster:     aws.String(cluster),
+		ServiceName: aws.String(service),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		taskARNs = append(taskARNs, page.TaskArns...)
+	}
+
+	return taskARNs, nil
+}
+
+// showClusterServiceLogs shows the logs of every task of service in cluster. A task that cannot be shown is
+// logged with the cluster prefix and skipped; the failed tasks are reported once the others were shown.
+func showClusterServiceLogs(ctx context.Context, w io.Writer, ecsClient ecsAPI, cwLogsClient logEventsAPI, cluster string,
+	service string, logGroupName string, index *logIndex,
+) error {
+	taskARNs, err := listServiceTasks(ctx, ecsClient, cluster, service)
+	if err != nil {
+		return err
+	}
+
+	if len(taskARNs) == 0 {
+		return fmt.Errorf("no running tasks of service %s", service)
+	}
+
+	failed := 0
+
+	for _, taskARN := range taskARNs {
+		if _, err := fmt.Fprintf(w, "Task: %s\n", taskARN); err != nil {
+			return fmt.Errorf("failed to write task: %w", err)
+		}
+
+		task, err := describeTask(ctx, ecsClient, cluster, taskARN)
+		if err == nil {
+			err = showTaskLogs(ctx, w, ecsClient, cwLogsClient, cluster, task, logGroupName, newLogEventPrinter(w, index))
+		}
+
+		if err != nil {
+			log.Printf("[%s] failed to show task %s: %v", cluster, taskARN, err)
+
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to show %d of %d task(s)", failed, len(taskARNs))
+	}
+
+	return nil
+}
+
+// showServiceLogs shows the logs of the tasks of service in each cluster, prefixing the lines with the
+// cluster name. An error in one cluster is reported after the other clusters were shown.
+func showServiceLogs(ctx context.Context, w io.Writer, ecsClient ecsAPI, cwLogsClient logEventsAPI, clusters []string,
+	service string, logGroupName string, index *logIndex,
+) error {
+	var errs []error
+
+	for _, cluster := range clusters {
+		err := showClusterServiceLogs(ctx, newClusterWriter(w, cluster), ecsClient, cwLogsClient, cluster, service, logGroupName, index)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestShowServiceLogsContinuesPastFailingClusterAndTask(t *testing.T) {
+	var logs bytes.Buffer
+
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&logs)
+	log.SetFlags(0)
+
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	})
+
+	broken := testTask("prod", "abc", "web")
+	healthy := testTask("prod", "def", "web")
+
+	ecsClient := &fakeECS{
+		tasks:        map[string][]ecsTypes.Task{"prod": {broken, healthy}},
+		failing:      map[string]bool{"legacy": true},
+		failingTasks: map[string]bool{*broken.TaskArn: true},
+	}
+	logEvents := &fakeLogs{events: map[string][]string{"web": {"listening on :8080"}}}
+
+	var out bytes.Buffer
+
+	err := showServiceLogs(context.Background(), &out, ecsClient, logEvents, []string{"legacy", "prod"}, "web", "/ecs/app", nil)
+	if err == nil {
+		t.Fatal("showServiceLogs returned no error for the failing cluster and task")
+	}
+
+	for _, want := range []string{"cluster legacy: failed to list tasks", "cluster prod: failed to show 1 of 2 task(s)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	if want := "[prod] failed to show task " + *broken.TaskArn + ": failed to describe tasks"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs.String())
+	}
+
+	for _, line := range []string{
+		"[prod] Task: " + *broken.TaskArn + "\n",
+		"[prod] Task: " + *healthy.TaskArn + "\n[prod] Log Stream Name: web\n",
+		"\tlistening on :8080\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("output is missing %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestParseClusters(t *testing.T) {
+	clusters, err := parseClusters("prod,staging")
+	if err != nil || len(clusters) != 2 || clusters[0] != "prod" || clusters[1] != "staging" {
+		t.Errorf("parseClusters = %v, %v, want [prod staging]", clusters, err)
+	}
+
+	for _, value := range []string{"", "prod,", ",prod", "prod,,staging"} {
+		if _, err := parseClusters(value); err == nil {
+			t.Errorf("parseClusters(%q) returned no error", value)
+		}
+	}
+}
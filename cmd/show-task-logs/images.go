@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// printTaskContainerImages writes the image and image digest of each container of task, which tie the
+// logs to a specific image build. The digest is missing until the container image was pulled.
+func printTaskContainerImages(w io.Writer, task ecsTypes.Task) error {
+	for _, container := range task.Containers {
+		digest := aws.ToString(container.ImageDigest)
+		if digest == "" {
+			digest = "unknown"
+		}
+
+		_, err := fmt.Fprintf(w, "Container Image: %s %s (digest %s)\n", aws.ToString(container.Name), aws.ToString(container.Image), digest)
+		if err != nil {
+			return fmt.Errorf("failed to write container images: %w", err)
+		}
+	}
+
+	return nil
+}
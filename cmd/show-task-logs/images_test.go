@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestShowTaskLogsContainerImages(t *testing.T) {
+	const digest = "sha256:0b3e7c1f9a2d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7"
+
+	task := testTask("prod", "abc", "web")
+	task.Containers[0].Image = aws.String("111111111111.dkr.ecr.us-west-2.amazonaws.com/web:1.4.2")
+	task.Containers[0].ImageDigest = aws.String(digest)
+	// The sidecar's image has not been pulled yet.
+	task.Containers = append(task.Containers, ecsTypes.Container{Name: aws.String("envoy"), Image: aws.String("envoyproxy/envoy:v1.31")})
+
+	output := runShowTaskLogs(t, &fakeECS{}, &fakeLogs{}, "prod", task)
+
+	for _, line := range []string{
+		"Container Image: web 111111111111.dkr.ecr.us-west-2.amazonaws.com/web:1.4.2 (digest " + digest + ")\n",
+		"Container Image: envoy envoyproxy/envoy:v1.31 (digest unknown)\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("output is missing %q:\n%s", line, output)
+		}
+	}
+}
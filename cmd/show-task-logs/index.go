@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// IndexEventsFile holds the indexed log events, one JSON object per line.
+	IndexEventsFile = "events.ndjson"
+	// IndexTokensFile maps each token to the line numbers of the events that contain it.
+	IndexTokensFile = "tokens.json"
+
+	indexDirMode     = 0o750
+	indexFileMode    = 0o600
+	maxIndexLineSize = 1024 * 1024
+)
+
+// indexedLogEvent is a log event as stored in the local index.
+type indexedLogEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	LogStream string `json:"logStream"`
+	Message   string `json:"message"`
+}
+
+// logIndex is a simple on-disk inverted index of log events: the events in NDJSON plus a token map.
+// Events added to an existing index are appended to it.
+type logIndex struct {
+	dir    string
+	events *os.File
+	tokens map[string][]int
+	lines  int
+}
+
+// tokenize splits text into lowercase runs of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// loadIndexTokens reads the token map of the index in dir; a missing map is an empty index.
+func loadIndexTokens(dir string) (map[string][]int, error) {
+	tokens := map[string][]int{}
+
+	data, err := os.ReadFile(filepath.Join(dir, IndexTokensFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index tokens: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse index tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// readIndexEvents reads every event of the index in dir, in line order.
+func readIndexEvents(dir string) ([]indexedLogEvent, error) {
+	file, err := os.Open(filepath.Join(dir, IndexEventsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index events: %w", err)
+	}
+	defer file.Close()
+
+	var events []indexedLogEvent
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxIndexLineSize)
+
+	for scanner.Scan() {
+		var event indexedLogEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse index event %d: %w", len(events), err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index events: %w", err)
+	}
+
+	return events, nil
+}
+
+// openLogIndex opens the index in dir for appending, creating it when needed.
+func openLogIndex(dir string) (*logIndex, error) {
+	if err := os.MkdirAll(dir, indexDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	tokens, err := loadIndexTokens(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := readIndexEvents(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := os.OpenFile(filepath.Join(dir, IndexEventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, indexFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index events: %w", err)
+	}
+
+	return &logIndex{dir: dir, events: events, tokens: tokens, lines: len(existing)}, nil
+}
+
+// Add appends event to the index.
+func (ix *logIndex) Add(event indexedLogEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log event: %w", err)
+	}
+
+	if _, err := ix.events.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write log event: %w", err)
+	}
+
+	seen := map[string]bool{}
+
+	for _, token := range tokenize(event.Message) {
+		if !seen[token] {
+			ix.tokens[token] = append(ix.tokens[token], ix.lines)
+			seen[token] = true
+		}
+	}
+
+	ix.lines++
+
+	return nil
+}
+
+// Close writes the token map and closes the index.
+func (ix *logIndex) Close() error {
+	if err := ix.events.Close(); err != nil {
+		return fmt.Errorf("failed to close index events: %w", err)
+	}
+
+	data, err := json.Marshal(ix.tokens)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index tokens: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(ix.dir, IndexTokensFile), data, indexFileMode); err != nil {
+		return fmt.Errorf("failed to write index tokens: %w", err)
+	}
+
+	return nil
+}
+
+// searchLogIndex returns the events of the index in dir that contain every token of query, in line order.
+func searchLogIndex(dir string, query string) ([]indexedLogEvent, error) {
+	queryTokens := uniqueStrings(tokenize(query))
+	if len(queryTokens) == 0 {
+		return nil, fmt.Errorf("search query has no searchable words: %q", query)
+	}
+
+	tokens, err := loadIndexTokens(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[int]int{}
+
+	for _, token := range queryTokens {
+		for _, line := range tokens[token] {
+			counts[line]++
+		}
+	}
+
+	var lines []int
+
+	for line, count := range counts {
+		if count == len(queryTokens) {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	sort.Ints(lines)
+
+	events, err := readIndexEvents(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]indexedLogEvent, 0, len(lines))
+
+	for _, line := range lines {
+		if line < len(events) {
+			matches = append(matches, events[line])
+		}
+	}
+
+	return matches, nil
+}
+
+// uniqueStrings returns values without duplicates, keeping the first occurrence of each.
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if !seen[value] {
+			unique = append(unique, value)
+			seen[value] = true
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// indexLogEvents writes messages, as log events of stream, into the index in dir.
+func indexLogEvents(t *testing.T, dir string, stream string, messages ...string) {
+	t.Helper()
+
+	index, err := openLogIndex(dir)
+	if err != nil {
+		t.Fatalf("openLogIndex error: %v", err)
+	}
+
+	for i, message := range messages {
+		if err := index.Add(indexedLogEvent{Timestamp: int64(i), LogStream: stream, Message: message}); err != nil {
+			t.Fatalf("index Add error: %v", err)
+		}
+	}
+
+	if err := index.Close(); err != nil {
+		t.Fatalf("index Close error: %v", err)
+	}
+}
+
+// searchMessages returns the streams and messages of the events of the index in dir matching query.
+func searchMessages(t *testing.T, dir string, query string) []string {
+	t.Helper()
+
+	events, err := searchLogIndex(dir, query)
+	if err != nil {
+		t.Fatalf("searchLogIndex(%q) error: %v", query, err)
+	}
+
+	matches := make([]string, 0, len(events))
+	for _, event := range events {
+		matches = append(matches, event.LogStream+": "+event.Message)
+	}
+
+	return matches
+}
+
+func TestIndexThenSearch(t *testing.T) {
+	dir := t.TempDir()
+
+	indexLogEvents(t, dir, "web",
+		"GET /health 200",
+		"ERROR connection refused to db:5432",
+		"GET /orders 500",
+		"error: Connection reset by peer",
+	)
+	// A second run appends to the index.
+	indexLogEvents(t, dir, "worker", "job 42 failed: connection refused", "job 43 done")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"connection refused", []string{
+			"web: ERROR connection refused to db:5432",
+			"worker: job 42 failed: connection refused",
+		}},
+		{"Error CONNECTION", []string{"web: ERROR connection refused to db:5432", "web: error: Connection reset by peer"}},
+		{"GET 500", []string{"web: GET /orders 500"}},
+		{"job 43", []string{"worker: job 43 done"}},
+		{"timeout", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := searchMessages(t, dir, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("search %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	if _, err := searchLogIndex(dir, " -- "); err == nil {
+		t.Error("search without words returned no error")
+	}
+}
+
+func TestSearchRequiresIndex(t *testing.T) {
+	if _, err := parseOptions("show-task-logs", []string{"-search", "error"}); err == nil {
+		t.Error("-search without -index returned no error")
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
 const (
@@ -35,20 +37,33 @@ func getCloudWatchLogsClient(ctx context.Context, region string) (*cloudwatchlog
 	return cloudwatchlogs.NewFromConfig(cfg), nil
 }
 
-func getTaskLogStreamName(ctx context.Context, ecsClient *ecs.Client, cluster string, taskID string) (string, error) {
+// ecsAPI is the subset of the ECS client used by show-task-logs.
+type ecsAPI interface {
+	DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error)
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
+	ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error)
+}
+
+// logEventsAPI is the subset of the CloudWatch Logs client used by show-task-logs.
+type logEventsAPI = cloudwatchlogs.GetLogEventsAPIClient
+
+func describeTask(ctx context.Context, ecsClient ecsAPI, cluster string, taskID string) (*ecsTypes.Task, error) {
 	resp, err := ecsClient.DescribeTasks(ctx, &ecs.DescribeTasksInput{
 		Cluster: aws.String(cluster),
 		Tasks:   []string{taskID},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to describe tasks: %w", err)
+		return nil, fmt.Errorf("failed to describe tasks: %w", err)
 	}
 
 	if len(resp.Tasks) == 0 {
-		return "", fmt.Errorf("task not found")
+		return nil, fmt.Errorf("task not found")
 	}
 
-	task := resp.Tasks[0]
+	return &resp.Tasks[0], nil
+}
+
+func getTaskLogStreamName(task *ecsTypes.Task) (string, error) {
 	if len(task.Containers) == 0 {
 		return "", fmt.Errorf("no containers found in task")
 	}
@@ -57,7 +72,36 @@ func getTaskLogStreamName(ctx context.Context, ecsClient *ecs.Client, cluster st
 	return *container.Name, nil
 }
 
-func getLogEvents(ctx context.Context, cwLogsClient *cloudwatchlogs.Client, logGroupName string, logStreamName string) error {
+// logEventFunc handles a single log event as it is fetched.
+type logEventFunc func(event indexedLogEvent) error
+
+func printLogEvent(event indexedLogEvent) error {
+	return writeLogEvent(os.Stdout, event)
+}
+
+// writeLogEvent writes event to w.
+func writeLogEvent(w io.Writer, event indexedLogEvent) error {
+	if _, err := fmt.Fprintf(w, "%s\t%s\n", time.UnixMilli(event.Timestamp).String(), event.Message); err != nil {
+		return fmt.Errorf("failed to write log event: %w", err)
+	}
+
+	return nil
+}
+
+// newLogEventPrinter returns a logEventFunc writing the events to w and, when index is not nil, adding them to index.
+func newLogEventPrinter(w io.Writer, index *logIndex) logEventFunc {
+	return func(event indexedLogEvent) error {
+		_ = writeLogEvent(w, event)
+
+		if index == nil {
+			return nil
+		}
+
+		return index.Add(event)
+	}
+}
+
+func getLogEvents(ctx context.Context, cwLogsClient logEventsAPI, logGroupName string, logStreamName string, onEvent logEventFunc) error {
 	endTime := time.Now()
 	startTime := endTime.Add(-1 * time.Hour)
 
@@ -76,29 +120,85 @@ func getLogEvents(ctx context.Context, cwLogsClient *cloudwatchlogs.Client, logG
 		}
 
 		for _, event := range page.Events {
-			fmt.Printf("%s\t%s\n", time.UnixMilli(*event.Timestamp).String(), *event.Message)
+			err = onEvent(indexedLogEvent{
+				Timestamp: aws.ToInt64(event.Timestamp),
+				LogStream: logStreamName,
+				Message:   aws.ToString(event.Message),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// showTaskLogs writes the log stream, container image, network and capacity provider details of task in cluster to w,
+// then passes its log events to onEvent.
+func showTaskLogs(ctx context.Context, w io.Writer, ecsClient ecsAPI, cwLogsClient logEventsAPI, cluster string,
+	task *ecsTypes.Task, logGroupName string, onEvent logEventFunc,
+) error {
+	logStreamName, err := getTaskLogStreamName(task)
+	if err != nil {
+		return fmt.Errorf("failed to get log stream name: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "Log Stream Name: %s\n", logStreamName); err != nil {
+		return fmt.Errorf("failed to write log stream name: %w", err)
+	}
+
+	if err := printTaskContainerImages(w, *task); err != nil {
+		return fmt.Errorf("failed to print container images: %w", err)
+	}
+
+	if err := printTaskNetworkDetails(w, *task); err != nil {
+		return fmt.Errorf("failed to print network details: %w", err)
+	}
+
+	var clusterDetails *ecsTypes.Cluster
+	if task.CapacityProviderName != nil {
+		clusterDetails, err = describeCluster(ctx, ecsClient, cluster)
+		if err != nil {
+			log.Printf("failed to describe cluster %s: %v", cluster, err)
 		}
 	}
 
+	if err := printTaskCapacityProvider(w, *task, clusterDetails); err != nil {
+		return fmt.Errorf("failed to print capacity provider details: %w", err)
+	}
+
+	if err := getLogEvents(ctx, cwLogsClient, logGroupName, logStreamName, onEvent); err != nil {
+		return fmt.Errorf("failed to get log events: %w", err)
+	}
+
 	return nil
 }
 
 func main() {
 	ctx := context.Background()
 
-	region := os.Getenv("AWS_REGION")
-	if region == "" {
-		region = "us-west-2"
+	opts, err := parseOptions(os.Args[0], os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to parse options: %v", err)
 	}
 
-	cluster := os.Getenv("ECS_CLUSTER")
-	if cluster == "" {
-		panic("ECS_CLUSTER environment variable is required")
+	if opts.search != "" {
+		matches, err := searchLogIndex(opts.indexDir, opts.search)
+		if err != nil {
+			log.Fatalf("failed to search index: %v", err)
+		}
+
+		for _, event := range matches {
+			_ = printLogEvent(event)
+		}
+
+		return
 	}
 
-	taskID := os.Getenv("ECS_TASK_ID")
-	if taskID == "" {
-		panic("ECS_TASK_ID environment variable is required")
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-west-2"
 	}
 
 	logGroupName := os.Getenv("LOG_GROUP_NAME")
@@ -116,15 +216,47 @@ func main() {
 		log.Fatalf("failed to create CloudWatch Logs client: %v", err)
 	}
 
-	logStreamName, err := getTaskLogStreamName(ctx, ecsClient, cluster, taskID)
-	if err != nil {
-		log.Fatalf("failed to get log stream name: %v", err)
+	var index *logIndex
+
+	if opts.indexDir != "" {
+		index, err = openLogIndex(opts.indexDir)
+		if err != nil {
+			log.Fatalf("failed to open index: %v", err)
+		}
+
+		defer func() {
+			if err := index.Close(); err != nil {
+				log.Fatalf("failed to write index: %v", err)
+			}
+		}()
+	}
+
+	if len(opts.clusters) > 0 {
+		err = showServiceLogs(ctx, os.Stdout, ecsClient, cwLogsClient, opts.clusters, opts.service, logGroupName, index)
+		if err != nil {
+			log.Fatalf("failed to show service logs: %v", err)
+		}
+
+		return
 	}
 
-	fmt.Printf("Log Stream Name: %s\n", logStreamName)
+	cluster := os.Getenv("ECS_CLUSTER")
+	if cluster == "" {
+		panic("ECS_CLUSTER environment variable is required")
+	}
+
+	taskID := os.Getenv("ECS_TASK_ID")
+	if taskID == "" {
+		panic("ECS_TASK_ID environment variable is required")
+	}
+
+	task, err := describeTask(ctx, ecsClient, cluster, taskID)
+	if err != nil {
+		log.Fatalf("failed to describe task: %v", err)
+	}
 
-	err = getLogEvents(ctx, cwLogsClient, logGroupName, logStreamName)
+	err = showTaskLogs(ctx, os.Stdout, ecsClient, cwLogsClient, cluster, task, logGroupName, newLogEventPrinter(os.Stdout, index))
 	if err != nil {
-		log.Fatalf("failed to get log events: %v", err)
+		log.Fatalf("failed to show task logs: %v", err)
 	}
 }
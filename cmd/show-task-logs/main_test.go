@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwlTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// fakeECS serves the tasks and clusters of an ECS account.
+type fakeECS struct {
+	// tasks are the tasks of each cluster.
+	tasks map[string][]ecsTypes.Task
+	// clusters are the cluster descriptions, by name.
+	clusters map[string]ecsTypes.Cluster
+	// failing are the clusters whose calls fail.
+	failing map[string]bool
+	// failingTasks are the task ARNs DescribeTasks fails for.
+	failingTasks map[string]bool
+}
+
+func (f *fakeECS) DescribeTasks(_ context.Context, params *ecs.DescribeTasksInput, _ ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	cluster := aws.ToString(params.Cluster)
+	if f.failing[cluster] {
+		return nil, fmt.Errorf("cluster %s not found", cluster)
+	}
+
+	output := &ecs.DescribeTasksOutput{}
+
+	for _, task := range f.tasks[cluster] {
+		if !slices.Contains(params.Tasks, aws.ToString(task.TaskArn)) {
+			continue
+		}
+
+		if f.failingTasks[aws.ToString(task.TaskArn)] {
+			return nil, fmt.Errorf("task %s is unavailable", aws.ToString(task.TaskArn))
+		}
+
+		output.Tasks = append(output.Tasks, task)
+	}
+
+	return output, nil
+}
+
+func (f *fakeECS) DescribeClusters(_ context.Context, params *ecs.DescribeClustersInput, _ ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	output := &ecs.DescribeClustersOutput{}
+
+	for _, name := range params.Clusters {
+		if cluster, ok := f.clusters[name]; ok {
+			output.Clusters = append(output.Clusters, cluster)
+		}
+	}
+
+	return output, nil
+}
+
+func (f *fakeECS) ListTasks(_ context.Context, params *ecs.ListTasksInput, _ ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	cluster := aws.ToString(params.Cluster)
+	if f.failing[cluster] {
+		return nil, fmt.Errorf("cluster %s not found", cluster)
+	}
+
+	output := &ecs.ListTasksOutput{}
+	for _, task := range f.tasks[cluster] {
+		output.TaskArns = append(output.TaskArns, aws.ToString(task.TaskArn))
+	}
+
+	return output, nil
+}
+
+// fakeLogs serves the log events of each log stream in a single page.
+type fakeLogs struct {
+	events map[string][]string
+}
+
+func (f *fakeLogs) GetLogEvents(_ context.Context, params *cloudwatchlogs.GetLogEventsInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	output := &cloudwatchlogs.GetLogEventsOutput{}
+
+	for i, message := range f.events[aws.ToString(params.LogStreamName)] {
+		output.Events = append(output.Events, cwlTypes.OutputLogEvent{Timestamp: aws.Int64(int64(i)), Message: aws.String(message)})
+	}
+
+	return output, nil
+}
+
+// testTask returns a task of cluster with a single container named container.
+func testTask(cluster string, id string, container string) ecsTypes.Task {
+	return ecsTypes.Task{
+		TaskArn:    aws.String(fmt.Sprintf("arn:aws:ecs:us-west-2:111111111111:task/%s/%s", cluster, id)),
+		Containers: []ecsTypes.Container{{Name: aws.String(container)}},
+	}
+}
+
+// runShowTaskLogs runs showTaskLogs for task and returns what it wrote, with the log events printed after the details.
+func runShowTaskLogs(t *testing.T, ecsClient ecsAPI, logs *fakeLogs, cluster string, task ecsTypes.Task) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := showTaskLogs(context.Background(), &buf, ecsClient, logs, cluster, &task, "/ecs/app", newLogEventPrinter(&buf, nil)); err != nil {
+		t.Fatalf("showTaskLogs error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestShowTaskLogs(t *testing.T) {
+	task := testTask("prod", "abc", "web")
+	logs := &fakeLogs{events: map[string][]string{"web": {"started", "listening on :8080"}}}
+
+	output := runShowTaskLogs(t, &fakeECS{}, logs, "prod", task)
+
+	if !strings.HasPrefix(output, "Log Stream Name: web\n") {
+		t.Errorf("output does not start with the log stream name:\n%s", output)
+	}
+
+	for _, message := range []string{"\tstarted\n", "\tlistening on :8080\n"} {
+		if !strings.Contains(output, message) {
+			t.Errorf("output is missing log event %q:\n%s", message, output)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	// ENIAttachmentType is the attachment type of the elastic network interface of an awsvpc task.
+	ENIAttachmentType = "ElasticNetworkInterface"
+)
+
+// attachmentDetail returns the value of the named detail of attachment, or "" when absent.
+func attachmentDetail(attachment ecsTypes.Attachment, name string) string {
+	for _, detail := range attachment.Details {
+		if aws.ToString(detail.Name) == name {
+			return aws.ToString(detail.Value)
+		}
+	}
+
+	return ""
+}
+
+// printTaskNetworkDetails writes the networking details of task. For awsvpc tasks these are the
+// ENI, private IP and subnet from the task attachments; for bridge and host networking, where the
+// task has no ENI of its own, these are the containers' port bindings.
+func printTaskNetworkDetails(w io.Writer, task ecsTypes.Task) error {
+	var lines []string
+
+	for _, attachment := range task.Attachments {
+		if aws.ToString(attachment.Type) != ENIAttachmentType {
+			continue
+		}
+
+		lines = append(lines,
+			"Network Mode: awsvpc",
+			fmt.Sprintf("ENI: %s (%s)", attachmentDetail(attachment, "networkInterfaceId"), aws.ToString(attachment.Status)),
+			fmt.Sprintf("Private IP: %s", attachmentDetail(attachment, "privateIPv4Address")),
+			fmt.Sprintf("Subnet: %s", attachmentDetail(attachment, "subnetId")),
+		)
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "Network Mode: bridge/host")
+
+		for _, container := range task.Containers {
+			for _, binding := range container.NetworkBindings {
+				lines = append(lines, fmt.Sprintf("Port Binding: %s %s:%d -> %d/%s", aws.ToString(container.Name),
+					aws.ToString(binding.BindIP), aws.ToInt32(binding.HostPort), aws.ToInt32(binding.ContainerPort), binding.Protocol))
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write network details: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// networkDetails returns the network details printTaskNetworkDetails writes for task.
+func networkDetails(t *testing.T, task ecsTypes.Task) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := printTaskNetworkDetails(&buf, task); err != nil {
+		t.Fatalf("printTaskNetworkDetails error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestPrintTaskNetworkDetailsAwsvpc(t *testing.T) {
+	task := testTask("prod", "abc", "web")
+	task.Attachments = []ecsTypes.Attachment{{
+		Type:   aws.String(ENIAttachmentType),
+		Status: aws.String("ATTACHED"),
+		Details: []ecsTypes.KeyValuePair{
+			{Name: aws.String("subnetId"), Value: aws.String("subnet-0a1b2c3d")},
+			{Name: aws.String("networkInterfaceId"), Value: aws.String("eni-0123456789abcdef0")},
+			{Name: aws.String("privateIPv4Address"), Value: aws.String("10.0.1.25")},
+		},
+	}}
+
+	output := networkDetails(t, task)
+
+	for _, line := range []string{
+		"Network Mode: awsvpc\n",
+		"ENI: eni-0123456789abcdef0 (ATTACHED)\n",
+		"Private IP: 10.0.1.25\n",
+		"Subnet: subnet-0a1b2c3d\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("output is missing %q:\n%s", line, output)
+		}
+	}
+}
+
+func TestPrintTaskNetworkDetailsBridge(t *testing.T) {
+	task := testTask("prod", "abc", "web")
+	task.Containers[0].NetworkBindings = []ecsTypes.NetworkBinding{{
+		BindIP:        aws.String("0.0.0.0"),
+		HostPort:      aws.Int32(32768),
+		ContainerPort: aws.Int32(8080),
+		Protocol:      ecsTypes.TransportProtocolTcp,
+	}}
+
+	output := networkDetails(t, task)
+
+	for _, line := range []string{"Network Mode: bridge/host\n", "Port Binding: web 0.0.0.0:32768 -> 8080/tcp\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("output is missing %q:\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output, "ENI:") {
+		t.Errorf("bridge task output lists an ENI:\n%s", output)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// options holds the command line settings for show-task-logs. The task itself is
+// selected with the ECS_CLUSTER, ECS_TASK_ID and LOG_GROUP_NAME environment variables,
+// or with -clusters and -service.
+type options struct {
+	// indexDir, when set, also writes the fetched log events into a local search index in this directory.
+	indexDir string
+	// search, when set, searches the index in indexDir for events containing every word, then exits.
+	search string
+	// clusters, when set, shows the tasks of service in each of these clusters instead of ECS_CLUSTER and ECS_TASK_ID.
+	clusters []string
+	// service is the service whose tasks are shown with clusters.
+	service string
+}
+
+// parseOptions parses the command line arguments (without the program name) into options.
+func parseOptions(name string, args []string) (*options, error) {
+	opts := options{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.indexDir, "index", "",
+		"also write the fetched log events into a local search index in this directory")
+	fs.StringVar(&opts.search, "search", "",
+		"search the -index directory for log events containing every word of this query, then exit")
+	fs.Func("clusters", "show the tasks of the -service in each of these comma-separated clusters, "+
+		"prefixing the lines with the cluster name (instead of ECS_CLUSTER and ECS_TASK_ID)", func(value string) error {
+		clusters, err := parseClusters(value)
+		opts.clusters = clusters
+
+		return err
+	})
+	fs.StringVar(&opts.service, "service", "",
+		"with -clusters, the service whose running tasks are shown")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if opts.search != "" && opts.indexDir == "" {
+		return nil, fmt.Errorf("-search requires -index")
+	}
+
+	if (len(opts.clusters) > 0) != (opts.service != "") {
+		return nil, fmt.Errorf("-clusters and -service must be used together")
+	}
+
+	return &opts, nil
+}